@@ -0,0 +1,150 @@
+// Package agent runs the loop letting a model use the tools of an MCP server:
+// the model is given the tools of the server, the tool calls it requests are
+// run with the client and their results fed back, until the model ends its
+// turn.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/vibeus/mcp"
+)
+
+// DefaultMaxIterations is the maximum number of model turns of a run, if
+// Agent.MaxIterations is not set.
+const DefaultMaxIterations = 10
+
+var (
+	ErrMaxIterations = errors.New("agent: maximum iterations reached")
+	// ErrStopReason is returned when the model stops its turn for another
+	// reason than ending it or requesting tool calls, such as reaching its
+	// maximum number of tokens.
+	ErrStopReason = errors.New("agent: unexpected stop reason")
+)
+
+// Client is the part of [mcp.ClientState] used by agents.
+type Client interface {
+	AllTools(ctx context.Context, opts ...mcp.PageOption) iter.Seq2[mcp.ToolSpec, error]
+	ToolCall(ctx context.Context, name string, args map[string]string) (mcp.ToolCallResponse, error)
+}
+
+// Agent drives a Model with the tools of a Client.
+type Agent struct {
+	Client Client
+	Model  Model
+	// System is the system prompt of the model.
+	System    string
+	MaxTokens int64
+	// MaxIterations bounds the number of model turns of a run, defaults to
+	// DefaultMaxIterations.
+	MaxIterations int
+	// MaxParallelCalls bounds the number of tool calls of a turn run
+	// concurrently. All the calls of a turn are run concurrently if not
+	// positive, and in order if 1.
+	MaxParallelCalls int
+	// Transcript is called with each message added to the conversation by a
+	// run, if not nil.
+	Transcript func(Message)
+}
+
+func New(client Client, model Model) *Agent {
+	return &Agent{Client: client, Model: model}
+}
+
+// Run continues the conversation of messages until the model ends its turn,
+// and returns the whole conversation. The conversation so far is returned
+// along with ErrMaxIterations if the model still requests tool calls after
+// MaxIterations turns, or with ErrStopReason if the model stops otherwise,
+// its last message being cut short.
+//
+// Tool calls failing are reported to the model as error results rather than
+// ending the run.
+func (a *Agent) Run(ctx context.Context, messages ...Message) ([]Message, error) {
+	var tools []Tool
+	for spec, err := range a.Client.AllTools(ctx) {
+		if err != nil {
+			return messages, err
+		}
+		tools = append(tools, ToolFromSpec(spec))
+	}
+
+	messages = append([]Message(nil), messages...)
+	add := func(msg Message) {
+		messages = append(messages, msg)
+		if a.Transcript != nil {
+			a.Transcript(msg)
+		}
+	}
+
+	maxIterations := a.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = DefaultMaxIterations
+	}
+	for range maxIterations {
+		res, err := a.Model.Generate(ctx, Request{
+			System:    a.System,
+			Messages:  messages,
+			Tools:     tools,
+			MaxTokens: a.MaxTokens,
+		})
+		if err != nil {
+			return messages, err
+		}
+		res.Message.Role = mcp.RoleAssistant
+		add(res.Message)
+		switch res.StopReason {
+		case mcp.StopReasonEndTurn, mcp.StopReasonStopSequence, StopReasonToolUse:
+		default:
+			return messages, fmt.Errorf("%w: %q", ErrStopReason, res.StopReason)
+		}
+		if len(res.Message.ToolCalls) == 0 {
+			return messages, nil
+		}
+
+		results, err := a.callTools(ctx, res.Message.ToolCalls)
+		if err != nil {
+			return messages, err
+		}
+		add(Message{Role: mcp.RoleUser, ToolResults: results})
+	}
+	return messages, ErrMaxIterations
+}
+
+// callTools runs calls, returning their results in the same order.
+func (a *Agent) callTools(ctx context.Context, calls []ToolCall) ([]ToolResult, error) {
+	limit := a.MaxParallelCalls
+	if limit <= 0 {
+		limit = len(calls)
+	}
+	results := make([]ToolResult, len(calls))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = a.callTool(ctx, call)
+		}()
+	}
+	wg.Wait()
+	return results, ctx.Err()
+}
+
+func (a *Agent) callTool(ctx context.Context, call ToolCall) ToolResult {
+	result := ToolResult{CallID: call.ID, Name: call.Name}
+	res, err := a.Client.ToolCall(ctx, call.Name, call.Arguments)
+	if err != nil {
+		result.Content = []mcp.Content{mcp.TextContent(err.Error())}
+		result.IsError = true
+		return result
+	}
+	result.Content = res.Content
+	result.IsError = res.IsError
+	return result
+}
@@ -0,0 +1,220 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vibeus/mcp"
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+// weatherTools serves a weather tool.
+type weatherTools struct {
+	started sync.Once
+}
+
+func (*weatherTools) NegotiateMCPVersion(string) string { return mcp.LatestMCPVersion }
+
+func (p *weatherTools) Tools_Started() *sync.Once        { return &p.started }
+func (p *weatherTools) Tools_Capability() *mcp.CapTools  { return new(mcp.CapTools) }
+func (p *weatherTools) Tools_ListChanged() chan struct{} { return nil }
+func (p *weatherTools) Tools_OnList(cursor string) []mcp.ListToolsResonponse {
+	return []mcp.ListToolsResonponse{{Tools: []mcp.ToolSpec{{
+		Name:        "weather",
+		Description: "Current weather of a city",
+		InputSchema: mcp.ToolSchema{
+			Type:       "object",
+			Properties: map[string]mcp.ParamSchema{"city": {Type: "string", Description: "City name"}},
+			Required:   []string{"city"},
+		},
+	}}}}
+}
+func (p *weatherTools) Tools_OnCall(name string, args map[string]string) (mcp.ToolCallResponse, *jsonrpc2.ErrorObject) {
+	if name != "weather" {
+		return mcp.ToolCallResponse{}, &jsonrpc2.ErrorObject{Code: -32602, Message: "Unknown tool"}
+	}
+	if args["city"] == "" {
+		return mcp.ToolCallResponse{Content: []mcp.Content{mcp.TextContent("city is required")}, IsError: true}, nil
+	}
+	return mcp.ToolCallResponse{Content: []mcp.Content{mcp.TextContent("Sunny in " + args["city"])}}, nil
+}
+
+func setupClient(t *testing.T) *mcp.ClientState {
+	t.Helper()
+	sconn, cconn := net.Pipe()
+	t.Cleanup(func() {
+		sconn.Close()
+		cconn.Close()
+	})
+
+	provider := new(weatherTools)
+	server := mcp.NewServer(sconn)
+	serverImpl := &mcp.ServerImpl{MCPVersionNegotiator: provider, CapToolsProvider: provider}
+	server.Setup(serverImpl)
+	server.SetMCPVersion(mcp.LatestMCPVersion)
+	server.SetCapabilities(serverImpl.Capabilities())
+	go server.Serve()
+
+	clientImpl := new(mcp.ClientImpl)
+	client := mcp.NewClient(cconn)
+	client.Setup(clientImpl)
+	client.SetMCPVersion(mcp.LatestMCPVersion)
+	client.SetCapabilities(clientImpl.Capabilities())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := client.Initialized(ctx); err != nil {
+		t.Fatalf("Initialized failed: %v", err)
+	}
+	return client
+}
+
+func TestAgent(t *testing.T) {
+	client := setupClient(t)
+	ctx := context.Background()
+
+	t.Run("ToolLoop", func(t *testing.T) {
+		model := NewScriptedModel(
+			CallTools(
+				ToolCall{ID: "1", Name: "weather", Arguments: map[string]string{"city": "Paris"}},
+				ToolCall{ID: "2", Name: "weather"},
+				ToolCall{ID: "3", Name: "forecast"},
+			),
+			Reply("It is sunny in Paris."),
+		)
+		agent := New(client, model)
+		agent.System = "You answer questions about the weather."
+		agent.MaxTokens = 100
+		var transcript []Message
+		agent.Transcript = func(msg Message) { transcript = append(transcript, msg) }
+
+		messages, err := agent.Run(ctx, UserMessage("How is the weather in Paris?"))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if len(messages) != 4 || messages[3].Text() != "It is sunny in Paris." {
+			t.Fatalf("Unexpected conversation: %+v", messages)
+		}
+		if len(transcript) != 3 || transcript[0].Role != mcp.RoleAssistant || transcript[1].Role != mcp.RoleUser {
+			t.Errorf("Unexpected transcript: %+v", transcript)
+		}
+
+		results := messages[2].ToolResults
+		if len(results) != 3 {
+			t.Fatalf("Expected 3 results, got %+v", results)
+		}
+		if results[0].CallID != "1" || results[0].IsError || results[0].Content[0].Text != "Sunny in Paris" {
+			t.Errorf("Unexpected result: %+v", results[0])
+		}
+		if results[1].CallID != "2" || !results[1].IsError {
+			t.Errorf("Expected tool error, got %+v", results[1])
+		}
+		if results[2].CallID != "3" || !results[2].IsError {
+			t.Errorf("Expected protocol error, got %+v", results[2])
+		}
+
+		requests := model.Requests()
+		if len(requests) != 2 || requests[0].System != agent.System || requests[0].MaxTokens != 100 ||
+			len(requests[0].Tools) != 1 || requests[0].Tools[0].InputSchema.Required[0] != "city" ||
+			len(requests[1].Messages) != 3 {
+			t.Errorf("Unexpected requests: %+v", requests)
+		}
+	})
+
+	t.Run("MaxIterations", func(t *testing.T) {
+		call := CallTools(ToolCall{ID: "1", Name: "weather", Arguments: map[string]string{"city": "Oslo"}})
+		agent := New(client, NewScriptedModel(call, call, call))
+		agent.MaxIterations = 2
+		messages, err := agent.Run(ctx, UserMessage("Loop"))
+		if !errors.Is(err, ErrMaxIterations) || len(messages) != 5 {
+			t.Errorf("Expected ErrMaxIterations after 5 messages, got %d, %v", len(messages), err)
+		}
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		truncated := Reply("It is sunny in")
+		truncated.StopReason = mcp.StopReasonMaxTokens
+		messages, err := New(client, NewScriptedModel(truncated)).Run(ctx, UserMessage("Weather?"))
+		if !errors.Is(err, ErrStopReason) || len(messages) != 2 || messages[1].Text() != "It is sunny in" {
+			t.Errorf("Expected ErrStopReason with the truncated message, got %+v, %v", messages, err)
+		}
+	})
+
+	t.Run("ModelError", func(t *testing.T) {
+		_, err := New(client, NewScriptedModel()).Run(ctx, UserMessage("Hi"))
+		if !errors.Is(err, ErrScriptExhausted) {
+			t.Errorf("Expected ErrScriptExhausted, got %v", err)
+		}
+	})
+}
+
+// barrierClient blocks its tool calls until n of them run concurrently.
+type barrierClient struct {
+	n       int
+	mutex   sync.Mutex
+	running int
+	peak    int
+	ready   chan struct{}
+}
+
+func (c *barrierClient) AllTools(ctx context.Context, opts ...mcp.PageOption) iter.Seq2[mcp.ToolSpec, error] {
+	return func(yield func(mcp.ToolSpec, error) bool) {}
+}
+
+func (c *barrierClient) ToolCall(ctx context.Context, name string, args map[string]string) (mcp.ToolCallResponse, error) {
+	c.mutex.Lock()
+	c.running++
+	c.peak = max(c.peak, c.running)
+	if c.running == c.n {
+		close(c.ready)
+	}
+	c.mutex.Unlock()
+
+	select {
+	case <-c.ready:
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	c.mutex.Lock()
+	c.running--
+	c.mutex.Unlock()
+	return mcp.ToolCallResponse{Content: []mcp.Content{mcp.TextContent(name)}}, nil
+}
+
+func TestAgentParallelCalls(t *testing.T) {
+	calls := CallTools(ToolCall{ID: "a", Name: "a"}, ToolCall{ID: "b", Name: "b"}, ToolCall{ID: "c", Name: "c"})
+	for _, tt := range []struct {
+		name     string
+		parallel int
+		peak     int
+	}{
+		{"Unbounded", 0, 3},
+		{"Bounded", 2, 2},
+		{"Sequential", 1, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &barrierClient{n: 3, ready: make(chan struct{})}
+			agent := New(client, NewScriptedModel(calls, Reply("done")))
+			agent.MaxParallelCalls = tt.parallel
+			messages, err := agent.Run(context.Background(), UserMessage("Go"))
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if client.peak != tt.peak {
+				t.Errorf("Expected %d concurrent calls, got %d", tt.peak, client.peak)
+			}
+			results := messages[2].ToolResults
+			if results[0].CallID != "a" || results[1].CallID != "b" || results[2].CallID != "c" {
+				t.Errorf("Results out of order: %+v", results)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/vibeus/mcp"
+)
+
+// StopReasonToolUse is the stop reason of the model turns requesting tool
+// calls.
+const StopReasonToolUse = "tool_use"
+
+// Tool is the definition of a tool as given to models.
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema mcp.ToolSchema
+}
+
+// ToolFromSpec returns the definition of the tool of spec.
+func ToolFromSpec(spec mcp.ToolSpec) Tool {
+	return Tool{Name: spec.Name, Description: spec.Description, InputSchema: spec.InputSchema}
+}
+
+// ToolCall is a call of a tool requested by a model.
+type ToolCall struct {
+	// identifier of the call, given back with its result
+	ID        string
+	Name      string
+	Arguments map[string]string
+}
+
+// ToolResult is the result of a ToolCall.
+type ToolResult struct {
+	CallID  string
+	Name    string
+	Content []mcp.Content
+	IsError bool
+}
+
+// Message is a message of the conversation with a model. Messages of the
+// assistant may request tool calls, and messages of the user carry their
+// results.
+type Message struct {
+	Role        string // mcp.RoleUser | mcp.RoleAssistant
+	Content     []mcp.Content
+	ToolCalls   []ToolCall
+	ToolResults []ToolResult
+}
+
+// Text returns the text content of m.
+func (m Message) Text() string {
+	var texts []string
+	for _, content := range m.Content {
+		if content.Type == mcp.ContentTypeText {
+			texts = append(texts, content.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// UserMessage returns a message of the user saying text.
+func UserMessage(text string) Message {
+	return Message{Role: mcp.RoleUser, Content: []mcp.Content{mcp.TextContent(text)}}
+}
+
+// Request is a turn of the conversation to be generated by a model.
+type Request struct {
+	System    string
+	Messages  []Message
+	Tools     []Tool
+	MaxTokens int64
+}
+
+// Response is the turn generated by a model.
+type Response struct {
+	Message Message
+	// mcp.StopReasonEndTurn, mcp.StopReasonStopSequence or StopReasonToolUse.
+	// Other reasons, such as mcp.StopReasonMaxTokens, fail the run of an
+	// agent.
+	StopReason string
+}
+
+// Model is the interface of the models driven by agents.
+type Model interface {
+	Generate(ctx context.Context, req Request) (Response, error)
+}
+
+// Reply returns a response of the model saying text and ending its turn.
+func Reply(text string) Response {
+	return Response{
+		Message:    Message{Role: mcp.RoleAssistant, Content: []mcp.Content{mcp.TextContent(text)}},
+		StopReason: mcp.StopReasonEndTurn,
+	}
+}
+
+// CallTools returns a response of the model requesting calls.
+func CallTools(calls ...ToolCall) Response {
+	return Response{
+		Message:    Message{Role: mcp.RoleAssistant, ToolCalls: calls},
+		StopReason: StopReasonToolUse,
+	}
+}
+
+var ErrScriptExhausted = errors.New("agent: scripted model has no more responses")
+
+// ScriptedModel is a deterministic Model replaying responses in order, to test
+// agents offline.
+type ScriptedModel struct {
+	mutex     sync.Mutex
+	responses []Response
+	requests  []Request
+}
+
+func NewScriptedModel(responses ...Response) *ScriptedModel {
+	return &ScriptedModel{responses: responses}
+}
+
+// Requests returns the requests received so far.
+func (m *ScriptedModel) Requests() []Request {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]Request(nil), m.requests...)
+}
+
+// Generate returns the next response of the script, or ErrScriptExhausted.
+func (m *ScriptedModel) Generate(ctx context.Context, req Request) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	req.Messages = append([]Message(nil), req.Messages...)
+	m.requests = append(m.requests, req)
+	if len(m.responses) == 0 {
+		return Response{}, ErrScriptExhausted
+	}
+	res := m.responses[0]
+	m.responses = m.responses[1:]
+	return res, nil
+}
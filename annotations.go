@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Annotations tell clients how to use or display resources and content.
+type Annotations struct {
+	// intended audience, RoleUser and/or RoleAssistant
+	Audience []string `json:"audience,omitempty"`
+	// importance from 0 (least important) to 1 (most important)
+	Priority *float64 `json:"priority,omitempty"`
+	// time of the last modification
+	LastModified *time.Time `json:"lastModified,omitempty"`
+}
+
+// Priority returns a pointer to v, to set [Annotations.Priority].
+func Priority(v float64) *float64 {
+	return &v
+}
+
+// IsFor reports whether role is part of the audience. Annotations without an
+// audience are for everyone.
+func (a *Annotations) IsFor(role string) bool {
+	return a == nil || len(a.Audience) == 0 || slices.Contains(a.Audience, role)
+}
+
+// priority returns the priority of a, and whether it is set.
+func (a *Annotations) priority() (float64, bool) {
+	if a == nil || a.Priority == nil {
+		return 0, false
+	}
+	return *a.Priority, true
+}
+
+// FilterResourcesByAudience returns the resources intended for role, including
+// the resources without an audience.
+func FilterResourcesByAudience(resources []ResourceSpec, role string) []ResourceSpec {
+	var filtered []ResourceSpec
+	for _, resource := range resources {
+		if resource.Annotations.IsFor(role) {
+			filtered = append(filtered, resource)
+		}
+	}
+	return filtered
+}
+
+// FilterResourcesByPriority returns the resources of a priority of at least
+// minPriority. Resources without a priority are dropped.
+func FilterResourcesByPriority(resources []ResourceSpec, minPriority float64) []ResourceSpec {
+	var filtered []ResourceSpec
+	for _, resource := range resources {
+		if priority, ok := resource.Annotations.priority(); ok && priority >= minPriority {
+			filtered = append(filtered, resource)
+		}
+	}
+	return filtered
+}
+
+// SortResourcesByPriority sorts resources from the most to the least
+// important, followed by the resources without a priority. The order of
+// resources of the same priority is kept.
+func SortResourcesByPriority(resources []ResourceSpec) {
+	slices.SortStableFunc(resources, func(a, b ResourceSpec) int {
+		pa, oka := a.Annotations.priority()
+		pb, okb := b.Annotations.priority()
+		switch {
+		case oka != okb:
+			if oka {
+				return -1
+			}
+			return 1
+		case pa > pb:
+			return -1
+		case pa < pb:
+			return 1
+		}
+		return 0
+	})
+}
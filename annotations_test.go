@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAnnotations(t *testing.T) {
+	modified := time.Date(2025, 1, 12, 15, 0, 58, 0, time.UTC)
+	resources := []ResourceSpec{
+		{URI: "file:///none", Name: "none"},
+		{URI: "file:///low", Name: "low", Annotations: &Annotations{Audience: []string{RoleUser}, Priority: Priority(0.2)}},
+		{URI: "file:///high", Name: "high", Annotations: &Annotations{Audience: []string{RoleAssistant}, Priority: Priority(0.9), LastModified: &modified}},
+		{URI: "file:///both", Name: "both", Annotations: &Annotations{Audience: []string{RoleUser, RoleAssistant}, Priority: Priority(0.5)}},
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		data, err := json.Marshal(resources[2])
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		want := `{"uri":"file:///high","name":"high","annotations":{"audience":["assistant"],"priority":0.9,"lastModified":"2025-01-12T15:00:58Z"}}`
+		if string(data) != want {
+			t.Errorf("Expected %s, got %s", want, data)
+		}
+	})
+
+	names := func(resources []ResourceSpec) string {
+		var s string
+		for _, resource := range resources {
+			s += resource.Name + " "
+		}
+		return s
+	}
+
+	t.Run("Filter", func(t *testing.T) {
+		if got := names(FilterResourcesByAudience(resources, RoleUser)); got != "none low both " {
+			t.Errorf("Unexpected resources for user: %s", got)
+		}
+		if got := names(FilterResourcesByAudience(resources, RoleAssistant)); got != "none high both " {
+			t.Errorf("Unexpected resources for assistant: %s", got)
+		}
+		if got := names(FilterResourcesByPriority(resources, 0.5)); got != "high both " {
+			t.Errorf("Unexpected resources by priority: %s", got)
+		}
+	})
+
+	t.Run("Sort", func(t *testing.T) {
+		sorted := append([]ResourceSpec(nil), resources...)
+		SortResourcesByPriority(sorted)
+		if got := names(sorted); got != "high both low none " {
+			t.Errorf("Unexpected order: %s", got)
+		}
+	})
+}
+
+func TestResourceLinks(t *testing.T) {
+	link := ResourceLinkContent(ResourceSpec{
+		URI:         "resource://test/0",
+		Name:        "report",
+		MimeType:    "text/plain",
+		Size:        21,
+		Annotations: &Annotations{Priority: Priority(1)},
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		data, err := json.Marshal(link)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		want := `{"type":"resource_link","uri":"resource://test/0","name":"report","mimeType":"text/plain","size":21,"annotations":{"priority":1}}`
+		if string(data) != want {
+			t.Errorf("Expected %s, got %s", want, data)
+		}
+		var decoded Content
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if decoded.Link == nil || decoded.Link.URI != "resource://test/0" || decoded.Annotations != nil || decoded.Validate() != nil {
+			t.Errorf("Unexpected content: %+v", decoded)
+		}
+		if err := (Content{Type: ContentTypeResourceLink, Link: &ResourceSpec{URI: "x"}}).Validate(); err == nil {
+			t.Error("Expected link without name to be invalid")
+		}
+	})
+
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapToolsProvider:     serverProvider,
+		CapResourcesProvider: serverProvider,
+	}
+	serverInstance.Use(func(ctx context.Context, inv *Invocation, next MethodHandler) (any, error) {
+		if inv.Method == kMethodToolsCall {
+			return ToolCallResponse{Content: []Content{TextContent("see the report"), link}}, nil
+		}
+		return next(ctx, inv)
+	})
+	clientProvider := &testClientImpl{roots_ListChanged: make(chan struct{})}
+	clientInstance := &ClientImpl{
+		CapRootsProvider:    clientProvider,
+		CapSamplingProvider: clientProvider,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("FollowLink", func(t *testing.T) {
+		response, err := ts.Client.ToolCall(ts.Ctx, "report", nil)
+		if err != nil {
+			t.Fatalf("ToolCall failed: %v", err)
+		}
+		if len(response.Content) != 2 || response.Content[1].Type != ContentTypeResourceLink {
+			t.Fatalf("Unexpected content: %+v", response.Content)
+		}
+		content, err := ts.Client.ResourcesRead(ts.Ctx, response.Content[1].Link.URI)
+		if err != nil || len(content) != 1 || content[0].Text != "Test resource content" {
+			t.Errorf("Unexpected linked resource: %v, %v", content, err)
+		}
+	})
+}
@@ -19,11 +19,20 @@ type CapSamplingProvider interface {
 	jsonrpc2.HandlerOf[SamplingMessage, SamplingResponse]
 }
 
+// CapSamplingCreator can be implemented by a [CapSamplingProvider] to create
+// messages with a context, done when the session ends. It is called in its own
+// goroutine instead of HandleRequest, so that waiting for a model or a user
+// does not hold up the other requests of the server. Errors other than
+// [jsonrpc2.ErrorObject] are answered as internal errors.
+type CapSamplingCreator interface {
+	Sampling_OnCreateMessage(ctx context.Context, msg SamplingMessage) (SamplingResponse, error)
+}
+
 type CapPromptsProvider interface {
 	Prompts_Started() *sync.Once
 	Prompts_Capability() *CapPrompts
 	Prompts_OnList(cursor string) []ListPromptsResponse
-	Prompts_OnGet(name string) (PromptGetResponse, *jsonrpc2.ErrorObject)
+	Prompts_OnGet(name string, args map[string]string) (PromptGetResponse, *jsonrpc2.ErrorObject)
 	Prompts_ListChanged() chan struct{}
 }
 
@@ -44,11 +53,66 @@ type CapResourcesProvider interface {
 	Resources_ListChanged() chan struct{}
 }
 
+// CapResourceTemplatesPager can be implemented by a [CapResourcesProvider] to
+// serve its resource templates in pages, like the other lists. Providers that
+// do not implement it serve all their templates in a single page from
+// Resources_OnTemplatesList. An invalid cursor is answered with an error,
+// usually invalid params.
+type CapResourceTemplatesPager interface {
+	Resources_OnTemplatesListPage(cursor string) (ResourcesTemplatesListResponse, *jsonrpc2.ErrorObject)
+}
+
+// CapPromptsFinder can be implemented by a [CapPromptsProvider] to look up the
+// prompt name, whose required arguments are checked before Prompts_OnGet is
+// called. The prompts of other providers are looked up in the pages they list.
+type CapPromptsFinder interface {
+	Prompts_Find(name string) (PromptSpec, bool)
+}
+
+// CapToolsLister can be implemented by a [CapToolsProvider] to answer
+// tools/list with an error, such as the failure of a backend, where
+// Tools_OnList can only list no tools. Tools_List is called instead of
+// Tools_OnList.
+type CapToolsLister interface {
+	Tools_List(cursor string) ([]ListToolsResonponse, *jsonrpc2.ErrorObject)
+}
+
+// CapResourcesReader can be implemented by a [CapResourcesProvider] to answer
+// reads with an error, such as the failure of a backend, where
+// Resources_OnRead can only report the resource as not found. Resources_Read
+// is called instead of Resources_OnRead.
+type CapResourcesReader interface {
+	Resources_Read(uri string) ([]ResourceContentUnion, *jsonrpc2.ErrorObject)
+}
+
+// CapResourcesWatcher can be implemented by a [CapResourcesProvider] to report
+// changes to its resources. Resources_Watch is called in its own goroutine
+// once per session, and must return when ctx is done.
+type CapResourcesWatcher interface {
+	Resources_Watch(ctx context.Context, changes ResourceChangeReporter)
+}
+
+// ResourceChangeReporter turns the changes reported by a [CapResourcesWatcher]
+// into notifications.
+type ResourceChangeReporter interface {
+	// ListChanged reports that resources were added or removed.
+	ListChanged()
+	// Updated reports that the content of the resource at uri changed. It is
+	// only notified if the client subscribed to uri.
+	Updated(uri string)
+	// Failed reports that the resources cannot be watched, which is logged.
+	Failed(err error)
+}
+
 type Root struct {
 	URI  string `json:"uri"`
 	Name string `json:"name,omitempty"`
 }
 
+type RootsListResponse struct {
+	Roots []Root `json:"roots"`
+}
+
 type PagedRequest struct {
 	Cursor string `json:"cursor,omitempty"`
 }
@@ -60,8 +124,10 @@ type ListPromptsResponse struct {
 
 type PromptSpec struct {
 	Name        string         `json:"name"`
+	Title       string         `json:"title,omitempty"` // human readable name
 	Description string         `json:"description,omitempty"`
 	Arguments   []ArgumentSpec `json:"arguments"`
+	Meta        map[string]any `json:"_meta,omitempty"`
 }
 
 type ArgumentSpec struct {
@@ -76,13 +142,8 @@ type PromptGetRequest struct {
 }
 
 type MessageWithRole struct {
-	Role    string          `json:"role"`
-	Content ContentTextOnly `json:"content"`
-}
-
-type ContentTextOnly struct {
-	Type string `json:"type"` // text
-	Text string `json:"text"`
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
 }
 
 type PromptGetResponse struct {
@@ -96,9 +157,12 @@ type ListToolsResonponse struct {
 }
 
 type ToolSpec struct {
-	Name        string     `json:"name"`
-	Description string     `json:"description,omitempty"`
-	InputSchema ToolSchema `json:"input_schema"`
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"` // human readable name
+	Description string           `json:"description,omitempty"`
+	InputSchema ToolSchema       `json:"input_schema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+	Meta        map[string]any   `json:"_meta,omitempty"`
 }
 
 type ToolSchema struct {
@@ -108,8 +172,9 @@ type ToolSchema struct {
 }
 
 type ParamSchema struct {
-	Type        string `json:"type"`
-	Description string `json:"description"`
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Enum        []string `json:"enum,omitempty"` // allowed values, if not empty
 }
 
 type ToolCallRequest struct {
@@ -118,49 +183,8 @@ type ToolCallRequest struct {
 }
 
 type ToolCallResponse struct {
-	Content []ToolCallContentUnion `json:"content"`
-	IsError bool                   `json:"isError"`
-}
-
-// Text Content
-//
-//	{
-//	  "type": "text",
-//	  "text": "Tool result text"
-//	}
-//
-// Image Content
-//
-//	{
-//		"type": "image",
-//		"data": "base64-encoded-data",
-//		"mimeType": "image/png"
-//	}
-//
-// Audio Content
-//
-//	{
-//	  "type": "audio",
-//	  "data": "base64-encoded-audio-data",
-//	  "mimeType": "audio/wav"
-//	}
-//
-// EmbeddedResource
-//
-//	{
-//		"type": "resource",
-//		"resource": {
-//		  "uri": "resource://example",
-//		  "mimeType": "text/plain",
-//		  "text": "Resource content"
-//		}
-//	}
-type ToolCallContentUnion struct {
-	Type     string                `json:"type"` // text | image | audio | resource
-	Text     string                `json:"text,omitempty"`
-	MimeType string                `json:"mimeType,omitempty"`
-	Data     string                `json:"data,omitempty"` // base64 encoded image | audio data
-	Resource *ResourceContentUnion `json:"resource,omitempty"`
+	Content []Content `json:"content"`
+	IsError bool      `json:"isError"`
 }
 
 type ResourcesListResponse struct {
@@ -170,21 +194,29 @@ type ResourcesListResponse struct {
 
 type ResourcesTemplatesListResponse struct {
 	ResourceTemplates []ResourceTemplateSpec `json:"resourceTemplates"`
+	NextCursor        string                 `json:"nextCursor,omitempty"`
 }
 
 type ResourcesReadRequest struct {
 	URI string `json:"uri"`
 }
+
+type ResourcesSubscribeRequest struct {
+	URI string `json:"uri"`
+}
 type ResourcesReadResponse struct {
 	Content []ResourceContentUnion `json:"content"`
 }
 
 type ResourceSpec struct {
-	URI         string `json:"uri"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	MimeType    string `json:"mimeType,omitempty"`
-	Size        int64  `json:"size,omitempty"` // size in bytes
+	URI         string         `json:"uri"`
+	Name        string         `json:"name"`
+	Title       string         `json:"title,omitempty"` // human readable name
+	Description string         `json:"description,omitempty"`
+	MimeType    string         `json:"mimeType,omitempty"`
+	Size        int64          `json:"size,omitempty"` // size in bytes
+	Annotations *Annotations   `json:"annotations,omitempty"`
+	Meta        map[string]any `json:"_meta,omitempty"`
 }
 
 // Text Content
@@ -203,17 +235,21 @@ type ResourceSpec struct {
 //		"blob": "base64-encoded-data"
 //	}
 type ResourceContentUnion struct {
-	URI      string `json:"uri"`
-	MimeType string `json:"mimeType,omitempty"`
-	Text     string `json:"text,omitempty"`
-	Blob     string `json:"blob,omitempty"` // base64 encoded binary resource data
+	URI         string       `json:"uri"`
+	MimeType    string       `json:"mimeType,omitempty"`
+	Text        string       `json:"text,omitempty"`
+	Blob        string       `json:"blob,omitempty"` // base64 encoded binary resource data
+	Annotations *Annotations `json:"annotations,omitempty"`
 }
 
 type ResourceTemplateSpec struct {
-	URITemplate string `json:"uriTemplate"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	MimeType    string `json:"mimeType"`
+	URITemplate string         `json:"uriTemplate"`
+	Name        string         `json:"name"`
+	Title       string         `json:"title,omitempty"` // human readable name
+	Description string         `json:"description"`
+	MimeType    string         `json:"mimeType"`
+	Annotations *Annotations   `json:"annotations,omitempty"`
+	Meta        map[string]any `json:"_meta,omitempty"`
 }
 
 func startCapRoots(client *ClientState, roots CapRootsProvider) {
@@ -234,11 +270,13 @@ func startCapRoots(client *ClientState, roots CapRootsProvider) {
 				}
 				wg.Done()
 
-				context.AfterFunc(client.ctx, func() {
-					close(ch)
-				})
-				for range ch {
-					client.NotifyRootsListChanged(client.ctx)
+				for {
+					select {
+					case <-client.ctx.Done():
+						return
+					case <-ch:
+						client.NotifyRootsListChanged(client.ctx)
+					}
 				}
 			}()
 		}
@@ -264,11 +302,13 @@ func startCapPrompts(server *ServerState, prompts CapPromptsProvider) {
 				}
 				wg.Done()
 
-				context.AfterFunc(server.ctx, func() {
-					close(ch)
-				})
-				for range ch {
-					server.NotifyPromptsListChanged(server.ctx)
+				for {
+					select {
+					case <-server.ctx.Done():
+						return
+					case <-ch:
+						server.NotifyPromptsListChanged(server.ctx)
+					}
 				}
 			}()
 		}
@@ -294,11 +334,13 @@ func startCapTools(server *ServerState, tools CapToolsProvider) {
 				}
 				wg.Done()
 
-				context.AfterFunc(server.ctx, func() {
-					close(ch)
-				})
-				for range ch {
-					server.NotifyToolsListChanged(server.ctx)
+				for {
+					select {
+					case <-server.ctx.Done():
+						return
+					case <-ch:
+						server.NotifyToolsListChanged(server.ctx)
+					}
 				}
 			}()
 		}
@@ -324,11 +366,13 @@ func startCapResources(server *ServerState, resources CapResourcesProvider) {
 				}
 				wg.Done()
 
-				context.AfterFunc(server.ctx, func() {
-					close(ch)
-				})
-				for range ch {
-					server.NotifyResourcesListChanged(server.ctx)
+				for {
+					select {
+					case <-server.ctx.Done():
+						return
+					case <-ch:
+						server.NotifyResourcesListChanged(server.ctx)
+					}
 				}
 			}()
 		}
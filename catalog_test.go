@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCatalogCache(t *testing.T) {
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapPromptsProvider:   serverProvider,
+		CapToolsProvider:     serverProvider,
+		CapResourcesProvider: serverProvider,
+	}
+	// serve tools on two pages and count the calls
+	var toolsListCalls atomic.Int32
+	serverInstance.Use(func(ctx context.Context, inv *Invocation, next MethodHandler) (any, error) {
+		if inv.Method != kMethodToolsList {
+			return next(ctx, inv)
+		}
+		toolsListCalls.Add(1)
+		if inv.Params.(*PagedRequest).Cursor == "" {
+			return []ListToolsResonponse{{Tools: []ToolSpec{{Name: "first_tool"}}, NextCursor: "page2"}}, nil
+		}
+		return next(ctx, inv)
+	})
+
+	clientProvider := &testClientImpl{roots_ListChanged: make(chan struct{})}
+	clientInstance := &ClientImpl{
+		CapRootsProvider:    clientProvider,
+		CapSamplingProvider: clientProvider,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	cache := ts.Client.EnableCatalogCache()
+	if ts.Client.Catalog() != cache {
+		t.Fatal("Expected Catalog to return the enabled cache")
+	}
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("FillLazily", func(t *testing.T) {
+		for range 3 {
+			tools, err := cache.Tools(ts.Ctx)
+			if err != nil {
+				t.Fatalf("Tools failed: %v", err)
+			}
+			if len(tools) != 2 || tools[0].Name != "first_tool" || tools[1].Name != "test_tool" {
+				t.Fatalf("Unexpected tools: %v", tools)
+			}
+		}
+		if calls := toolsListCalls.Load(); calls != 2 {
+			t.Errorf("Expected 2 tools/list calls, got %d", calls)
+		}
+
+		prompts, err := cache.Prompts(ts.Ctx)
+		if err != nil || len(prompts) != 1 {
+			t.Errorf("Unexpected prompts: %v, %v", prompts, err)
+		}
+		resources, err := cache.Resources(ts.Ctx)
+		if err != nil || len(resources) != 1 {
+			t.Errorf("Unexpected resources: %v, %v", resources, err)
+		}
+		templates, err := cache.ResourceTemplates(ts.Ctx)
+		if err != nil || len(templates) != 1 {
+			t.Errorf("Unexpected templates: %v, %v", templates, err)
+		}
+	})
+
+	t.Run("Invalidation", func(t *testing.T) {
+		version := cache.Version()
+		changed := cache.Changed()
+		serverProvider.tools_ListChanged <- struct{}{}
+
+		select {
+		case <-changed:
+		case <-time.After(1 * time.Second):
+			t.Fatal("Timeout waiting for catalog change")
+		}
+		if cache.Version() != version+1 {
+			t.Errorf("Expected version %d, got %d", version+1, cache.Version())
+		}
+
+		if _, err := cache.Tools(ts.Ctx); err != nil {
+			t.Fatalf("Tools failed: %v", err)
+		}
+		if calls := toolsListCalls.Load(); calls != 4 {
+			t.Errorf("Expected 4 tools/list calls, got %d", calls)
+		}
+	})
+}
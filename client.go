@@ -32,6 +32,9 @@ type ClientState struct {
 	rpc  *jsonrpc2.Peer
 
 	timeoutConfig ClientTimeout
+	interceptors  []Interceptor
+	notifications notificationHandlers
+	catalog       *CatalogCache
 }
 
 func NewClient(conn io.ReadWriteCloser) *ClientState {
@@ -44,9 +47,38 @@ func NewClient(conn io.ReadWriteCloser) *ClientState {
 	return client
 }
 
+// Connect sets up a client of the server at the other end of conn, with
+// provider, or a ClientImpl without capabilities if nil, and initializes the
+// session. The connection is closed if the session cannot be initialized.
+func Connect(ctx context.Context, conn io.ReadWriteCloser, provider ClientProvider, logger *slog.Logger) (*ClientState, error) {
+	if provider == nil {
+		provider = &ClientImpl{}
+	}
+	client := NewClient(conn)
+	client.Setup(provider)
+	if logger != nil {
+		client.SetLogger(logger)
+	}
+	client.SetMCPVersion(LatestMCPVersion)
+	client.SetCapabilities(provider.Capabilities())
+	if err := client.Initialize(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	if err := client.Initialized(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
 func (c *ClientState) Setup(impl ClientProvider) {
 	c.impl = impl
-	c.rpc = jsonrpc2.NewPeer(c.ctx, jsonrpc2.NewLineFramer(c.ctx.GetSession().GetConn()), impl)
+	c.rpc = jsonrpc2.NewPeer(c.ctx, jsonrpc2.NewLineFramer(c.ctx.GetSession().GetConn()), clientHandler{c})
+	c.notifications.queue = make(chan jsonrpc2.Request, DefaultNotificationQueueSize)
+	c.notifications.listChanged = make(map[string]bool)
+	c.notifications.wake = make(chan struct{}, 1)
+	go c.dispatchNotifications()
 }
 
 func (c *ClientState) SetLogger(logger *slog.Logger) {
@@ -55,6 +87,21 @@ func (c *ClientState) SetLogger(logger *slog.Logger) {
 	s.SetLogger(logger)
 }
 
+// Close ends the session of the client, closing its connection.
+func (c *ClientState) Close() {
+	c.ctx.GetSession().Close()
+}
+
+// Done returns a channel closed when the connection to the server is lost, or
+// the client closed.
+func (c *ClientState) Done() <-chan struct{} {
+	return c.rpc.Done()
+}
+
+func (c *ClientState) logger() *slog.Logger {
+	return c.ctx.GetSession().GetLogger()
+}
+
 func (c *ClientState) SetMCPVersion(version string) {
 	s := c.ctx.GetSession()
 	s.SetProtocolVersion(version)
@@ -69,24 +116,11 @@ func (c *ClientState) Ping(ctx context.Context) error {
 	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.PingTimeout)
 	defer cancel()
 
-	select {
-	case <-to_ctx.Done():
-		return to_ctx.Err()
-	default:
+	_, err := callOf[struct{}](to_ctx, c, kMethodPing, nil)
+	if err != nil {
 		s := c.ctx.GetSession()
-		logger := s.GetLogger()
-		if logger != nil {
-			logger.Debug(kMethodPing)
-		}
-		req, err := c.rpc.Call(kMethodPing, nil)
-		if err == nil {
-			err = req.RecvResponse(nil)
-		}
-
-		if err != nil {
-			s.SetMCPState(MCPState_End)
-			return err
-		}
+		s.SetMCPState(MCPState_End)
+		return err
 	}
 	return nil
 }
@@ -94,50 +128,29 @@ func (c *ClientState) Ping(ctx context.Context) error {
 // Initialize is called by the client to negotiate MCPVersion and Capabilities
 // with the server.
 func (c *ClientState) Initialize(ctx context.Context) error {
-	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.RPCTimeout)
-	defer cancel()
-
-	select {
-	case <-to_ctx.Done():
-		return to_ctx.Err()
-	default:
-		s := c.ctx.GetSession()
-		ci := new(ClientInitializeInfo)
-		ci.ProtocolVersion = s.GetProtocolVersion()
-		ci.ClientInfo = *s.GetClientInfo()
-		ci.Capabilities = *s.GetClientCapabilities()
-
-		si := new(ServerInitializeInfo)
-		logger := s.GetLogger()
-		req, err := c.rpc.Call(kMethodInitialize, ci)
-		if err == nil {
-			err = req.RecvResponse(&si)
-		}
-		if err != nil {
-			s.SetMCPState(MCPState_End)
-			return err
-		}
-		if logger != nil {
-			logger.Debug("Call done", "method", kMethodInitialize, "server", si)
-		}
+	s := c.ctx.GetSession()
+	ci := new(ClientInitializeInfo)
+	ci.ProtocolVersion = s.GetProtocolVersion()
+	ci.ClientInfo = *s.GetClientInfo()
+	ci.Capabilities = *s.GetClientCapabilities()
 
-		s.SetMCPState(MCPState_Initializing)
-		s.SetProtocolVersion(si.ProtocolVersion)
-		s.SetServerCapabilities(&si.Capabilities)
-		s.SetServerInfo(&si.ServerInfo)
+	si, err := callOf[ServerInitializeInfo](ctx, c, kMethodInitialize, ci)
+	if err != nil {
+		s.SetMCPState(MCPState_End)
+		return err
 	}
 
+	s.SetMCPState(MCPState_Initializing)
+	s.SetProtocolVersion(si.ProtocolVersion)
+	s.SetServerCapabilities(&si.Capabilities)
+	s.SetServerInfo(&si.ServerInfo)
 	return nil
 }
 
 // Initialized is called to notify the server that it has finished negotiating MCPVersion and Capabilities.
 func (c *ClientState) Initialized(ctx context.Context) error {
 	s := c.ctx.GetSession()
-	logger := s.GetLogger()
-	if logger != nil {
-		logger.Debug("Notify", "method", kMethodInitialized)
-	}
-	err := c.rpc.Notify(kMethodInitialized, nil)
+	err := c.notify(ctx, kMethodInitialized, nil)
 	if err != nil {
 		return err
 	}
@@ -152,83 +165,83 @@ func (c *ClientState) NotifyRootsListChanged(ctx context.Context) error {
 	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.PingTimeout)
 	defer cancel()
 
-	select {
-	case <-to_ctx.Done():
-		return to_ctx.Err()
-	default:
-		s := c.ctx.GetSession()
-		logger := s.GetLogger()
-		if logger != nil {
-			logger.Debug("Notify", "method", kMethodRootsListChanged)
-		}
-		return c.rpc.Notify(kMethodRootsListChanged, nil)
-	}
+	return c.notify(to_ctx, kMethodRootsListChanged, nil)
 }
 
-func (c *ClientState) PromptsList(ctx context.Context, cursor string) ([]ListPromptsResponse, error) {
-	s := c.ctx.GetSession()
-	sc := s.GetServerCapabilities()
-	if sc.Prompts == nil {
-		return nil, jsonrpc2.ErrObjMethodNotSupported
-	}
+// Use appends interceptors wrapping every outgoing MCP call made through c,
+// and the dispatch of the requests of the server handled by a [ClientImpl].
+// Interceptors must be installed before the client is initialized.
+func (c *ClientState) Use(interceptors ...Interceptor) {
+	c.interceptors = append(c.interceptors, interceptors...)
+}
 
-	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.RPCTimeout)
-	defer cancel()
+// callOf sends a request to the server through the interceptor chain of c and
+// decodes its result into R.
+func callOf[R any](ctx context.Context, c *ClientState, method string, params any) (R, error) {
+	final := func(ctx context.Context, inv *Invocation) (any, error) {
+		to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.RPCTimeout)
+		defer cancel()
 
-	select {
-	case <-to_ctx.Done():
-		return nil, to_ctx.Err()
-	default:
-		params := PagedRequest{Cursor: cursor}
-		s := c.ctx.GetSession()
-		logger := s.GetLogger()
+		logger := inv.Session.GetLogger()
 		if logger != nil {
-			logger.Debug("Call", "method", kMethodPromptsList, "params", params)
+			logger.Debug("Call", "method", inv.Method, "params", inv.Params)
+		}
+		result, err := jsonrpc2.CallOf[any, R](to_ctx, c.rpc, inv.Method, inv.Params)
+		if logger != nil {
+			logger.Debug("CallDone", "method", inv.Method, "result", result)
 		}
-		var result []ListPromptsResponse
-		req, err := c.rpc.Call(kMethodPromptsList, params)
 		if err != nil {
 			return nil, err
 		}
-		err = req.RecvResponse(&result)
-		if logger != nil {
-			logger.Debug("CallDone", "method", kMethodPromptsList, "result", result)
-		}
+		return result, nil
+	}
+
+	inv := &Invocation{Method: method, Params: params, Session: c.ctx.GetSession()}
+	res, err := chainInterceptors(c.interceptors, final)(ctx, inv)
+	if err != nil {
+		var result R
 		return result, err
 	}
+	return resultAs[R](res)
 }
 
-func (c *ClientState) PromptsGet(ctx context.Context, name string) (PromptGetResponse, error) {
+// notify sends a notification to the server through the interceptor chain of
+// c.
+func (c *ClientState) notify(ctx context.Context, method string, params any) error {
+	final := func(ctx context.Context, inv *Invocation) (any, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			logger := inv.Session.GetLogger()
+			if logger != nil {
+				logger.Debug("Notify", "method", inv.Method)
+			}
+			return nil, c.rpc.Notify(inv.Method, inv.Params)
+		}
+	}
+
+	inv := &Invocation{Method: method, Params: params, Session: c.ctx.GetSession()}
+	_, err := chainInterceptors(c.interceptors, final)(ctx, inv)
+	return err
+}
+
+func (c *ClientState) PromptsList(ctx context.Context, cursor string) ([]ListPromptsResponse, error) {
 	s := c.ctx.GetSession()
 	sc := s.GetServerCapabilities()
 	if sc.Prompts == nil {
-		return PromptGetResponse{}, jsonrpc2.ErrObjMethodNotSupported
+		return nil, jsonrpc2.ErrObjMethodNotSupported
 	}
+	return callOf[[]ListPromptsResponse](ctx, c, kMethodPromptsList, &PagedRequest{Cursor: cursor})
+}
 
-	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.RPCTimeout)
-	defer cancel()
-
-	select {
-	case <-to_ctx.Done():
-		return PromptGetResponse{}, to_ctx.Err()
-	default:
-		params := PromptGetRequest{Name: name}
-		s := c.ctx.GetSession()
-		logger := s.GetLogger()
-		if logger != nil {
-			logger.Debug("Call", "method", kMethodPromptsGet, "params", params)
-		}
-		var result PromptGetResponse
-		req, err := c.rpc.Call(kMethodPromptsGet, params)
-		if err != nil {
-			return result, err
-		}
-		err = req.RecvResponse(&result)
-		if logger != nil {
-			logger.Debug("CallDone", "method", kMethodPromptsGet, "result", result)
-		}
-		return result, err
+func (c *ClientState) PromptsGet(ctx context.Context, name string, args map[string]string) (PromptGetResponse, error) {
+	s := c.ctx.GetSession()
+	sc := s.GetServerCapabilities()
+	if sc.Prompts == nil {
+		return PromptGetResponse{}, jsonrpc2.ErrObjMethodNotSupported
 	}
+	return callOf[PromptGetResponse](ctx, c, kMethodPromptsGet, &PromptGetRequest{Name: name, Arguments: args})
 }
 
 func (c *ClientState) ToolsList(ctx context.Context, cursor string) ([]ListToolsResonponse, error) {
@@ -237,31 +250,7 @@ func (c *ClientState) ToolsList(ctx context.Context, cursor string) ([]ListTools
 	if sc.Tools == nil {
 		return nil, jsonrpc2.ErrObjMethodNotSupported
 	}
-
-	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.RPCTimeout)
-	defer cancel()
-
-	select {
-	case <-to_ctx.Done():
-		return nil, to_ctx.Err()
-	default:
-		params := PagedRequest{Cursor: cursor}
-		s := c.ctx.GetSession()
-		logger := s.GetLogger()
-		if logger != nil {
-			logger.Debug("Call", "method", kMethodToolsList, "params", params)
-		}
-		var result []ListToolsResonponse
-		req, err := c.rpc.Call(kMethodToolsList, params)
-		if err != nil {
-			return nil, err
-		}
-		err = req.RecvResponse(&result)
-		if logger != nil {
-			logger.Debug("CallDone", "method", kMethodToolsList, "result", result)
-		}
-		return result, err
-	}
+	return callOf[[]ListToolsResonponse](ctx, c, kMethodToolsList, &PagedRequest{Cursor: cursor})
 }
 
 func (c *ClientState) ToolCall(ctx context.Context, name string, args map[string]string) (ToolCallResponse, error) {
@@ -270,34 +259,11 @@ func (c *ClientState) ToolCall(ctx context.Context, name string, args map[string
 	if sc.Tools == nil {
 		return ToolCallResponse{}, jsonrpc2.ErrObjMethodNotSupported
 	}
-
-	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.RPCTimeout)
-	defer cancel()
-
-	select {
-	case <-to_ctx.Done():
-		return ToolCallResponse{}, to_ctx.Err()
-	default:
-		params := ToolCallRequest{
-			Name:      name,
-			Arguments: args,
-		}
-		s := c.ctx.GetSession()
-		logger := s.GetLogger()
-		if logger != nil {
-			logger.Debug("Call", "method", kMethodToolsCall, "params", params)
-		}
-		var result ToolCallResponse
-		req, err := c.rpc.Call(kMethodToolsCall, params)
-		if err != nil {
-			return result, err
-		}
-		err = req.RecvResponse(&result)
-		if logger != nil {
-			logger.Debug("CallDone", "method", kMethodToolsCall, "result", result)
-		}
-		return result, err
+	params := &ToolCallRequest{
+		Name:      name,
+		Arguments: args,
 	}
+	return callOf[ToolCallResponse](ctx, c, kMethodToolsCall, params)
 }
 
 func (c *ClientState) ResourcesList(ctx context.Context, cursor string) (ResourcesListResponse, error) {
@@ -306,64 +272,16 @@ func (c *ClientState) ResourcesList(ctx context.Context, cursor string) (Resourc
 	if sc.Resources == nil {
 		return ResourcesListResponse{}, jsonrpc2.ErrObjMethodNotSupported
 	}
-
-	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.RPCTimeout)
-	defer cancel()
-
-	select {
-	case <-to_ctx.Done():
-		return ResourcesListResponse{}, to_ctx.Err()
-	default:
-		params := PagedRequest{Cursor: cursor}
-		s := c.ctx.GetSession()
-		logger := s.GetLogger()
-		if logger != nil {
-			logger.Debug("Call", "method", kMethodResourcesList, "params", params)
-		}
-		var result ResourcesListResponse
-		req, err := c.rpc.Call(kMethodResourcesList, params)
-		if err != nil {
-			return ResourcesListResponse{}, err
-		}
-		err = req.RecvResponse(&result)
-		if logger != nil {
-			logger.Debug("CallDone", "method", kMethodResourcesList, "result", result)
-		}
-		return result, err
-	}
+	return callOf[ResourcesListResponse](ctx, c, kMethodResourcesList, &PagedRequest{Cursor: cursor})
 }
 
-func (c *ClientState) ResourcesTemplatesList(ctx context.Context) ([]ResourceTemplateSpec, error) {
+func (c *ClientState) ResourcesTemplatesList(ctx context.Context, cursor string) (ResourcesTemplatesListResponse, error) {
 	s := c.ctx.GetSession()
 	sc := s.GetServerCapabilities()
 	if sc.Resources == nil {
-		return nil, jsonrpc2.ErrObjMethodNotSupported
-	}
-
-	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.RPCTimeout)
-	defer cancel()
-
-	select {
-	case <-to_ctx.Done():
-		return nil, to_ctx.Err()
-	default:
-		params := PagedRequest{}
-		s := c.ctx.GetSession()
-		logger := s.GetLogger()
-		if logger != nil {
-			logger.Debug("Call", "method", kMethodResourcesTemplatesList, "params", params)
-		}
-		var result ResourcesTemplatesListResponse
-		req, err := c.rpc.Call(kMethodResourcesTemplatesList, params)
-		if err != nil {
-			return nil, err
-		}
-		err = req.RecvResponse(&result)
-		if logger != nil {
-			logger.Debug("CallDone", "method", kMethodResourcesTemplatesList, "result", result)
-		}
-		return result.ResourceTemplates, err
+		return ResourcesTemplatesListResponse{}, jsonrpc2.ErrObjMethodNotSupported
 	}
+	return callOf[ResourcesTemplatesListResponse](ctx, c, kMethodResourcesTemplatesList, &PagedRequest{Cursor: cursor})
 }
 
 // ResourcesRead reads the content of a specific resource by URI
@@ -373,29 +291,30 @@ func (c *ClientState) ResourcesRead(ctx context.Context, uri string) ([]Resource
 	if sc.Resources == nil {
 		return nil, jsonrpc2.ErrObjMethodNotSupported
 	}
+	result, err := callOf[ResourcesReadResponse](ctx, c, kMethodResourcesRead, &ResourcesReadRequest{URI: uri})
+	return result.Content, err
+}
 
-	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.RPCTimeout)
-	defer cancel()
+// ResourcesSubscribe subscribes to updates of the resource at uri, which are
+// delivered to the callbacks registered with [ClientState.OnResourceUpdated].
+func (c *ClientState) ResourcesSubscribe(ctx context.Context, uri string) error {
+	s := c.ctx.GetSession()
+	sc := s.GetServerCapabilities()
+	if sc.Resources == nil || !sc.Resources.Subscribe {
+		return jsonrpc2.ErrObjMethodNotSupported
+	}
+	_, err := callOf[struct{}](ctx, c, kMethodResourcesSubscribe, &ResourcesSubscribeRequest{URI: uri})
+	return err
+}
 
-	select {
-	case <-to_ctx.Done():
-		return nil, to_ctx.Err()
-	default:
-		params := ResourcesReadRequest{URI: uri}
-		s := c.ctx.GetSession()
-		logger := s.GetLogger()
-		if logger != nil {
-			logger.Debug("Call", "method", kMethodResourcesRead, "params", params)
-		}
-		var result ResourcesReadResponse
-		req, err := c.rpc.Call(kMethodResourcesRead, params)
-		if err != nil {
-			return nil, err
-		}
-		err = req.RecvResponse(&result)
-		if logger != nil {
-			logger.Debug("CallDone", "method", kMethodResourcesRead, "result", result)
-		}
-		return result.Content, err
+// ResourcesUnsubscribe cancels a subscription made with
+// [ClientState.ResourcesSubscribe].
+func (c *ClientState) ResourcesUnsubscribe(ctx context.Context, uri string) error {
+	s := c.ctx.GetSession()
+	sc := s.GetServerCapabilities()
+	if sc.Resources == nil || !sc.Resources.Subscribe {
+		return jsonrpc2.ErrObjMethodNotSupported
 	}
+	_, err := callOf[struct{}](ctx, c, kMethodResourcesUnsubscribe, &ResourcesSubscribeRequest{URI: uri})
+	return err
 }
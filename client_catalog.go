@@ -0,0 +1,154 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+)
+
+// CatalogCache caches the tools, prompts, resources and resource templates
+// offered by the server of a [ClientState]. Each list is fetched across all of
+// its pages the first time it is requested, and dropped when the server
+// notifies that it has changed.
+//
+// It is created with [ClientState.EnableCatalogCache].
+type CatalogCache struct {
+	client *ClientState
+
+	tools     catalogEntry[ToolSpec]
+	prompts   catalogEntry[PromptSpec]
+	resources catalogEntry[ResourceSpec]
+	templates catalogEntry[ResourceTemplateSpec]
+
+	mutex   sync.Mutex
+	version uint64
+	changed chan struct{}
+}
+
+// catalogEntry is a cached list. generation changes each time the list is
+// invalidated, so that a fetch started before an invalidation is not cached.
+type catalogEntry[T any] struct {
+	fetching   sync.Mutex
+	mutex      sync.Mutex
+	items      []T
+	loaded     bool
+	generation uint64
+}
+
+// EnableCatalogCache enables the catalog cache of c and returns it. It must be
+// called before the client is initialized; calling it again returns the same
+// cache.
+func (c *ClientState) EnableCatalogCache() *CatalogCache {
+	if c.catalog != nil {
+		return c.catalog
+	}
+	cache := &CatalogCache{client: c, changed: make(chan struct{})}
+	c.OnToolsListChanged(func() { invalidateEntry(cache, &cache.tools) })
+	c.OnPromptsListChanged(func() { invalidateEntry(cache, &cache.prompts) })
+	c.OnResourcesListChanged(func() {
+		invalidateEntry(cache, &cache.resources)
+		invalidateEntry(cache, &cache.templates)
+	})
+	c.catalog = cache
+	return cache
+}
+
+// Catalog returns the catalog cache of c, or nil if it is not enabled.
+func (c *ClientState) Catalog() *CatalogCache {
+	return c.catalog
+}
+
+// Version returns a counter incremented each time a cached list is
+// invalidated by the server.
+func (cache *CatalogCache) Version() uint64 {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.version
+}
+
+// Changed returns a channel closed at the next invalidation of a cached list.
+// Call it again after the channel is closed to wait for the following one.
+func (cache *CatalogCache) Changed() <-chan struct{} {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.changed
+}
+
+// Invalidate drops all cached lists, as if the server had notified that all of
+// them changed.
+func (cache *CatalogCache) Invalidate() {
+	invalidateEntry(cache, &cache.tools)
+	invalidateEntry(cache, &cache.prompts)
+	invalidateEntry(cache, &cache.resources)
+	invalidateEntry(cache, &cache.templates)
+}
+
+// Tools returns all the tools of the server.
+func (cache *CatalogCache) Tools(ctx context.Context) ([]ToolSpec, error) {
+	return loadEntry(ctx, &cache.tools, func(ctx context.Context) ([]ToolSpec, error) {
+		return collect(cache.client.AllTools(ctx))
+	})
+}
+
+// Prompts returns all the prompts of the server.
+func (cache *CatalogCache) Prompts(ctx context.Context) ([]PromptSpec, error) {
+	return loadEntry(ctx, &cache.prompts, func(ctx context.Context) ([]PromptSpec, error) {
+		return collect(cache.client.AllPrompts(ctx))
+	})
+}
+
+// Resources returns all the resources of the server.
+func (cache *CatalogCache) Resources(ctx context.Context) ([]ResourceSpec, error) {
+	return loadEntry(ctx, &cache.resources, func(ctx context.Context) ([]ResourceSpec, error) {
+		return collect(cache.client.AllResources(ctx))
+	})
+}
+
+// ResourceTemplates returns all the resource templates of the server.
+func (cache *CatalogCache) ResourceTemplates(ctx context.Context) ([]ResourceTemplateSpec, error) {
+	return loadEntry(ctx, &cache.templates, func(ctx context.Context) ([]ResourceTemplateSpec, error) {
+		return collect(cache.client.AllResourceTemplates(ctx))
+	})
+}
+
+// loadEntry returns the cached items of entry, fetching them if needed.
+// Concurrent callers wait for a single fetch.
+func loadEntry[T any](ctx context.Context, entry *catalogEntry[T], fetch func(context.Context) ([]T, error)) ([]T, error) {
+	entry.fetching.Lock()
+	defer entry.fetching.Unlock()
+
+	entry.mutex.Lock()
+	if entry.loaded {
+		items := entry.items
+		entry.mutex.Unlock()
+		return items, nil
+	}
+	generation := entry.generation
+	entry.mutex.Unlock()
+
+	items, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+	if entry.generation == generation {
+		entry.items = items
+		entry.loaded = true
+	}
+	return items, nil
+}
+
+func invalidateEntry[T any](cache *CatalogCache, entry *catalogEntry[T]) {
+	entry.mutex.Lock()
+	entry.items = nil
+	entry.loaded = false
+	entry.generation++
+	entry.mutex.Unlock()
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.version++
+	close(cache.changed)
+	cache.changed = make(chan struct{})
+}
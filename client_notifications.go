@@ -0,0 +1,241 @@
+package mcp
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+// DefaultNotificationQueueSize is the number of notifications a client buffers
+// while its callbacks catch up. Further notifications are dropped and logged,
+// except list_changed notifications which are coalesced instead, so that the
+// client never stops reading from the server.
+var DefaultNotificationQueueSize = 64
+
+// notificationCallbacks holds the callbacks registered on a [ClientState] for
+// notifications sent by the server.
+type notificationCallbacks struct {
+	toolsListChanged     callbackList[func()]
+	promptsListChanged   callbackList[func()]
+	resourcesListChanged callbackList[func()]
+	resourceUpdated      callbackList[func(uri string)]
+	logMessage           callbackList[func(LogMessageNotification)]
+	progress             callbackList[func(ProgressNotification)]
+}
+
+// callbackList is a list of callbacks, appended in place but replaced on
+// removal, so that a copy taken under the mutex is safe to use unlocked.
+type callbackList[F any] []*F
+
+// addCallback appends f to list under the mutex of n, and returns the function
+// removing it.
+func addCallback[F any](n *notificationHandlers, list *callbackList[F], f F) func() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	entry := &f
+	*list = append(*list, entry)
+	return func() {
+		n.mutex.Lock()
+		defer n.mutex.Unlock()
+		kept := make(callbackList[F], 0, len(*list))
+		for _, e := range *list {
+			if e != entry {
+				kept = append(kept, e)
+			}
+		}
+		*list = kept
+	}
+}
+
+type notificationHandlers struct {
+	mutex     sync.Mutex
+	callbacks notificationCallbacks
+	// methods of the list_changed notifications pending dispatch, signaled
+	// on wake
+	listChanged map[string]bool
+	wake        chan struct{}
+
+	queue chan jsonrpc2.Request
+}
+
+// listChangedMethods are the list_changed notifications, in the order they
+// are dispatched when pending together.
+var listChangedMethods = []string{kMethodToolsListChanged, kMethodPromptsListChanged, kMethodResourcesListChanged}
+
+// clientHandler handles the messages sent by the server to a client.
+// Notifications are dispatched to the callbacks of the client, requests are
+// handled by its provider.
+type clientHandler struct {
+	client *ClientState
+}
+
+func (h clientHandler) HandleRequest(w *jsonrpc2.ResponseWriter, req jsonrpc2.Request) error {
+	c := h.client
+	if req.IsNotification() {
+		c.queueNotification(req)
+		return nil
+	}
+	return c.impl.HandleRequest(w, req)
+}
+
+// OnToolsListChanged registers a callback called when the server notifies that
+// its list of tools has changed. It returns a function removing the callback.
+func (c *ClientState) OnToolsListChanged(f func()) func() {
+	return addCallback(&c.notifications, &c.notifications.callbacks.toolsListChanged, f)
+}
+
+// OnPromptsListChanged registers a callback called when the server notifies
+// that its list of prompts has changed. It returns a function removing the
+// callback.
+func (c *ClientState) OnPromptsListChanged(f func()) func() {
+	return addCallback(&c.notifications, &c.notifications.callbacks.promptsListChanged, f)
+}
+
+// OnResourcesListChanged registers a callback called when the server notifies
+// that its list of resources has changed. It returns a function removing the
+// callback.
+func (c *ClientState) OnResourcesListChanged(f func()) func() {
+	return addCallback(&c.notifications, &c.notifications.callbacks.resourcesListChanged, f)
+}
+
+// OnResourceUpdated registers a callback called with the URI of a subscribed
+// resource whose content has changed. It returns a function removing the
+// callback.
+func (c *ClientState) OnResourceUpdated(f func(uri string)) func() {
+	return addCallback(&c.notifications, &c.notifications.callbacks.resourceUpdated, f)
+}
+
+// OnLogMessage registers a callback called with the log messages sent by the
+// server. It returns a function removing the callback.
+func (c *ClientState) OnLogMessage(f func(LogMessageNotification)) func() {
+	return addCallback(&c.notifications, &c.notifications.callbacks.logMessage, f)
+}
+
+// OnProgress registers a callback called with the progress the server reports
+// for long-running requests. It returns a function removing the callback.
+func (c *ClientState) OnProgress(f func(ProgressNotification)) func() {
+	return addCallback(&c.notifications, &c.notifications.callbacks.progress, f)
+}
+
+// queueNotification queues req for dispatch without blocking the goroutine
+// reading from the server. A list_changed notification already pending is
+// coalesced with req, and other notifications are dropped once the queue is
+// full.
+func (c *ClientState) queueNotification(req jsonrpc2.Request) {
+	n := &c.notifications
+	if slices.Contains(listChangedMethods, req.Method) {
+		n.mutex.Lock()
+		n.listChanged[req.Method] = true
+		n.mutex.Unlock()
+		select {
+		case n.wake <- struct{}{}:
+		default:
+		}
+		return
+	}
+	select {
+	case n.queue <- req:
+	default:
+		if logger := c.ctx.GetSession().GetLogger(); logger != nil {
+			logger.Warn("Dropped notification, the queue is full", "method", req.Method)
+		}
+	}
+}
+
+// dispatchNotifications calls the registered callbacks for each notification
+// received, in order, list_changed notifications being coalesced. Callbacks
+// run outside of the goroutine reading from the server, so they may call the
+// server themselves.
+func (c *ClientState) dispatchNotifications() {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case req := <-c.notifications.queue:
+			c.dispatchNotification(req)
+		case <-c.notifications.wake:
+			c.notifications.mutex.Lock()
+			pending := c.notifications.listChanged
+			c.notifications.listChanged = make(map[string]bool)
+			c.notifications.mutex.Unlock()
+			for _, method := range listChangedMethods {
+				if pending[method] {
+					c.dispatchNotification(jsonrpc2.Request{Method: method})
+				}
+			}
+		}
+	}
+}
+
+func (c *ClientState) dispatchNotification(req jsonrpc2.Request) {
+	s := c.ctx.GetSession()
+	logger := s.GetLogger()
+	if logger != nil {
+		logger.Debug("Notification", "method", req.Method)
+	}
+
+	c.notifications.mutex.Lock()
+	h := c.notifications.callbacks
+	c.notifications.mutex.Unlock()
+
+	switch req.Method {
+	case kMethodToolsListChanged:
+		for _, f := range h.toolsListChanged {
+			(*f)()
+		}
+	case kMethodPromptsListChanged:
+		for _, f := range h.promptsListChanged {
+			(*f)()
+		}
+	case kMethodResourcesListChanged:
+		for _, f := range h.resourcesListChanged {
+			(*f)()
+		}
+	case kMethodResourcesUpdated:
+		var msg ResourceUpdatedNotification
+		if !decodeNotification(s, req, &msg) {
+			return
+		}
+		for _, f := range h.resourceUpdated {
+			(*f)(msg.URI)
+		}
+	case kMethodLoggingMessage:
+		var msg LogMessageNotification
+		if !decodeNotification(s, req, &msg) {
+			return
+		}
+		for _, f := range h.logMessage {
+			(*f)(msg)
+		}
+	case kMethodProgress:
+		var msg ProgressNotification
+		if !decodeNotification(s, req, &msg) {
+			return
+		}
+		for _, f := range h.progress {
+			(*f)(msg)
+		}
+	default:
+		if logger != nil {
+			logger.Debug("Unhandled notification", "method", req.Method)
+		}
+	}
+}
+
+func decodeNotification(s Session, req jsonrpc2.Request, v any) bool {
+	var err error
+	if req.Params == nil {
+		err = jsonrpc2.ErrObjInvalidParams
+	} else {
+		err = json.Unmarshal(*req.Params, v)
+	}
+	if err != nil {
+		if logger := s.GetLogger(); logger != nil {
+			logger.Error("Invalid notification", "method", req.Method, "error", err)
+		}
+		return false
+	}
+	return true
+}
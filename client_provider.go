@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
 	"sync"
 
@@ -51,29 +52,70 @@ func (c *ClientImpl) StartClientProvider() {
 	})
 }
 
+// HandleRequest handles the requests sent by the server, through the
+// interceptors of the client installed with [ClientState.Use]. Notifications
+// are dispatched by the [ClientState] and never reach the provider.
 func (c *ClientImpl) HandleRequest(w *jsonrpc2.ResponseWriter, req jsonrpc2.Request) error {
+	s := c.client.ctx.GetSession()
+	params, handler, erro := c.handle(req)
+	if erro != nil {
+		params, handler = nil, func(ctx context.Context, inv *Invocation) (any, error) {
+			return nil, erro
+		}
+	}
+	inv := &Invocation{Method: req.Method, Params: params, Session: s}
+	dispatch := func(ctx context.Context) error {
+		result, err := chainInterceptors(c.client.interceptors, handler)(ctx, inv)
+		if err != nil {
+			return w.WriteError(toErrorObject(err, s.GetLogger()))
+		}
+		return w.WriteResponse(result)
+	}
+
+	if _, ok := c.CapSamplingProvider.(CapSamplingCreator); ok && erro == nil && req.Method == kMethodSamplingCreateMessage {
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					perr := jsonrpc2.NewPanicError(s.GetLogger(), req.Method, r)
+					w.WriteError(jsonrpc2.ErrorObjectOf(perr))
+				}
+			}()
+			dispatch(w.Context())
+		}()
+		return nil
+	}
+	return dispatch(c.client.ctx)
+}
+
+// handle returns the decoded parameters of req and the handler answering it,
+// or the error answering it.
+func (c *ClientImpl) handle(req jsonrpc2.Request) (any, MethodHandler, *jsonrpc2.ErrorObject) {
 	switch req.Method {
+	case kMethodPing:
+		return nil, func(ctx context.Context, inv *Invocation) (any, error) {
+			return struct{}{}, nil
+		}, nil
 	case kMethodRootsList:
-		if c.CapRootsProvider != nil {
-			roots := c.CapRootsProvider.Roots_ListChanged()
-			w.WriteResponse(roots)
-		} else {
-			w.WriteError(jsonrpc2.ErrObjMethodNotSupported)
+		if c.CapRootsProvider == nil {
+			break
 		}
-		return nil
+		return nil, func(ctx context.Context, inv *Invocation) (any, error) {
+			return RootsListResponse{Roots: c.CapRootsProvider.Roots_OnList()}, nil
+		}, nil
 	case kMethodSamplingCreateMessage:
-		if c.CapSamplingProvider != nil {
-			var msg SamplingMessage
-			err := json.Unmarshal(*req.Params, &msg)
-			if err != nil {
-				w.WriteError(jsonrpc2.ErrObjInvalidParams)
-			}
-			return c.CapSamplingProvider.HandleRequest(jsonrpc2.MakeResponseWriterOf[SamplingResponse](w), msg)
-		} else {
-			w.WriteError(jsonrpc2.ErrObjMethodNotSupported)
+		if c.CapSamplingProvider == nil {
+			break
+		}
+		msg := new(SamplingMessage)
+		if req.Params == nil || json.Unmarshal(*req.Params, msg) != nil || msg.Validate() != nil {
+			return nil, nil, errObj(jsonrpc2.ErrObjInvalidParams)
 		}
-	default:
-		w.WriteError(jsonrpc2.ErrObjMethodNotSupported)
+		// creators are called in their own goroutine, with a context done when
+		// the session ends, so that waiting for a model or a user does not
+		// hold up the other requests of the server
+		return msg, func(ctx context.Context, inv *Invocation) (any, error) {
+			return createMessage(ctx, c.CapSamplingProvider, *msg)
+		}, nil
 	}
-	return nil
+	return nil, nil, errObj(jsonrpc2.ErrObjMethodNotSupported)
 }
@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/vibeus/mcp/transport"
+)
+
+// Transports of servers in a [Config].
+const (
+	ServerTypeStdio = "stdio"
+	ServerTypeHTTP  = "http"
+	ServerTypeSSE   = "sse"
+)
+
+var ErrInvalidConfig = errors.New("mcp: invalid configuration")
+
+// Config is the configuration of the servers of a host, in the mcpServers
+// format of the configuration files of desktop hosts:
+//
+//	{"mcpServers": {"files": {"command": "mcp-files", "args": ["${HOME}"]}}}
+type Config struct {
+	MCPServers map[string]ServerConfig `json:"mcpServers"`
+}
+
+// ServerConfig configures a server run as a subprocess, with Command, or
+// reached over HTTP, with URL.
+type ServerConfig struct {
+	// Type is the transport of the server, defaults to stdio for commands and
+	// to the streamable HTTP transport for URLs.
+	Type    string            `json:"type,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// LoadConfig reads the configuration file at path, see [ParseConfig].
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses a configuration, expanding the references to environment
+// variables in the strings of servers, either ${NAME} or ${NAME:-default}.
+// The errors of invalid servers wrap ErrInvalidConfig, as do references to
+// variables unset without default.
+func ParseConfig(data []byte) (*Config, error) {
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	var errs []error
+	for _, name := range config.Names() {
+		server, err := config.MCPServers[name].expand()
+		if err == nil {
+			err = server.validate()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: server %q: %v", ErrInvalidConfig, name, err))
+		}
+		config.MCPServers[name] = server
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &config, nil
+}
+
+// Names returns the names of the servers, sorted.
+func (c *Config) Names() []string {
+	return slices.Sorted(maps.Keys(c.MCPServers))
+}
+
+// HostServers returns the servers of a [Host], sorted by name.
+func (c *Config) HostServers() []HostServer {
+	var servers []HostServer
+	for _, name := range c.Names() {
+		servers = append(servers, HostServer{Name: name, Dial: c.MCPServers[name].Dialer().Dial})
+	}
+	return servers
+}
+
+// Connect connects to the server named name, see [Connect].
+func (c *Config) Connect(ctx context.Context, name string, provider ClientProvider, logger *slog.Logger) (*ClientState, error) {
+	server, ok := c.MCPServers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownServer, name)
+	}
+	conn, err := server.Dialer().Dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Connect(ctx, conn, provider, logger)
+}
+
+// Dialer returns the transport of the server. The environment of commands is
+// the environment of the process with Env, and their standard error is
+// discarded.
+func (s ServerConfig) Dialer() transport.Dialer {
+	switch s.Type {
+	case ServerTypeHTTP:
+		return &transport.StreamableHTTP{URL: s.URL, Header: s.header()}
+	case ServerTypeSSE:
+		return &transport.SSE{URL: s.URL, Header: s.header()}
+	default:
+		var env []string
+		if len(s.Env) > 0 {
+			env = os.Environ()
+			for _, name := range slices.Sorted(maps.Keys(s.Env)) {
+				env = append(env, name+"="+s.Env[name])
+			}
+		}
+		return &transport.Stdio{Command: s.Command, Args: s.Args, Env: env}
+	}
+}
+
+func (s ServerConfig) header() http.Header {
+	header := make(http.Header)
+	for name, value := range s.Headers {
+		header.Set(name, value)
+	}
+	return header
+}
+
+// validate checks s, setting its default type.
+func (s *ServerConfig) validate() error {
+	if s.Type == "" {
+		switch {
+		case s.Command != "" && s.URL != "":
+			return errors.New("both command and url")
+		case s.Command != "":
+			s.Type = ServerTypeStdio
+		case s.URL != "":
+			s.Type = ServerTypeHTTP
+		default:
+			return errors.New("no command or url")
+		}
+	}
+	if s.Type == "streamable-http" || s.Type == "streamableHttp" {
+		s.Type = ServerTypeHTTP
+	}
+
+	switch s.Type {
+	case ServerTypeStdio:
+		if s.Command == "" {
+			return errors.New("stdio server without command")
+		}
+		if s.URL != "" {
+			return errors.New("stdio server with url")
+		}
+	case ServerTypeHTTP, ServerTypeSSE:
+		if s.Command != "" {
+			return fmt.Errorf("%s server with command", s.Type)
+		}
+		u, err := url.Parse(s.URL)
+		if err != nil {
+			return err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("invalid url %q", s.URL)
+		}
+	default:
+		return fmt.Errorf("unknown type %q", s.Type)
+	}
+	return nil
+}
+
+// expand returns s with the references to environment variables expanded.
+func (s ServerConfig) expand() (ServerConfig, error) {
+	var err error
+	expand := func(v string) string {
+		if err != nil {
+			return v
+		}
+		v, err = expandEnv(v)
+		return v
+	}
+	expanded := ServerConfig{
+		Type:    s.Type,
+		Command: expand(s.Command),
+		URL:     expand(s.URL),
+	}
+	for _, arg := range s.Args {
+		expanded.Args = append(expanded.Args, expand(arg))
+	}
+	if s.Env != nil {
+		expanded.Env = make(map[string]string, len(s.Env))
+		for name, value := range s.Env {
+			expanded.Env[name] = expand(value)
+		}
+	}
+	if s.Headers != nil {
+		expanded.Headers = make(map[string]string, len(s.Headers))
+		for name, value := range s.Headers {
+			expanded.Headers[name] = expand(value)
+		}
+	}
+	return expanded, err
+}
+
+// expandEnv expands the references ${NAME} and ${NAME:-default} of s to
+// environment variables. Other dollar signs are kept.
+func expandEnv(s string) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference in %q", s)
+		}
+		name, fallback, hasFallback := strings.Cut(s[start+2:start+end], ":-")
+		value, ok := os.LookupEnv(name)
+		switch {
+		case ok && value != "":
+		case hasFallback:
+			value = fallback
+		case !ok:
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		b.WriteString(s[:start])
+		b.WriteString(value)
+		s = s[start+end+1:]
+	}
+}
@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stdioConn is the connection of a server to its standard input and output.
+type stdioConn struct {
+	io.Reader
+	io.Writer
+	closed chan struct{}
+}
+
+func (c *stdioConn) Close() error {
+	close(c.closed)
+	return nil
+}
+
+// TestConfigHelperServer serves the test server over its standard input and
+// output when run by TestConfigConnect.
+func TestConfigHelperServer(t *testing.T) {
+	if os.Getenv("MCP_CONFIG_HELPER_SERVER") != "1" {
+		return
+	}
+	conn := &stdioConn{Reader: os.Stdin, Writer: os.Stdout, closed: make(chan struct{})}
+	provider := NewTestServerImpl()
+	serverImpl := &ServerImpl{MCPVersionNegotiator: provider, CapToolsProvider: provider}
+	server := NewServer(conn)
+	server.Setup(serverImpl)
+	server.SetMCPVersion(LatestMCPVersion)
+	server.SetCapabilities(serverImpl.Capabilities())
+	server.Serve()
+	<-conn.closed
+	os.Exit(0)
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Setenv("MCP_TEST_TOKEN", "secret")
+	t.Setenv("MCP_TEST_EMPTY", "")
+	config, err := ParseConfig([]byte(`{
+		"mcpServers": {
+			"files": {
+				"command": "mcp-files",
+				"args": ["--root", "${MCP_TEST_ROOT:-/srv}", "$HOME"],
+				"env": {"TOKEN": "${MCP_TEST_TOKEN}", "EMPTY": "${MCP_TEST_EMPTY}"}
+			},
+			"search": {
+				"url": "https://example.com/mcp",
+				"headers": {"Authorization": "Bearer ${MCP_TEST_TOKEN}"}
+			},
+			"legacy": {"type": "sse", "url": "http://localhost:8080/sse"},
+			"remote": {"type": "streamable-http", "url": "http://localhost:8080/mcp"}
+		},
+		"theme": "dark"
+	}`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	want := map[string]ServerConfig{
+		"files": {
+			Type:    ServerTypeStdio,
+			Command: "mcp-files",
+			Args:    []string{"--root", "/srv", "$HOME"},
+			Env:     map[string]string{"TOKEN": "secret", "EMPTY": ""},
+		},
+		"search": {
+			Type:    ServerTypeHTTP,
+			URL:     "https://example.com/mcp",
+			Headers: map[string]string{"Authorization": "Bearer secret"},
+		},
+		"legacy": {Type: ServerTypeSSE, URL: "http://localhost:8080/sse"},
+		"remote": {Type: ServerTypeHTTP, URL: "http://localhost:8080/mcp"},
+	}
+	if !reflect.DeepEqual(config.MCPServers, want) {
+		t.Errorf("Expected %+v, got %+v", want, config.MCPServers)
+	}
+	if names := config.Names(); !reflect.DeepEqual(names, []string{"files", "legacy", "remote", "search"}) {
+		t.Errorf("Unexpected names: %v", names)
+	}
+
+	for _, tt := range []struct {
+		name, server, want string
+	}{
+		{"Empty", `{}`, "no command or url"},
+		{"Both", `{"command": "a", "url": "http://a"}`, "both command and url"},
+		{"UnknownType", `{"type": "ws", "url": "ws://a"}`, `unknown type "ws"`},
+		{"StdioURL", `{"type": "stdio", "url": "http://a"}`, "stdio server without command"},
+		{"Scheme", `{"url": "file:///a"}`, `invalid url "file:///a"`},
+		{"Unset", `{"command": "${MCP_TEST_UNSET}"}`, "environment variable MCP_TEST_UNSET is not set"},
+		{"Unterminated", `{"command": "a", "args": ["${MCP_TEST_TOKEN"]}`, "unterminated reference"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(`{"mcpServers": {"bad": ` + tt.server + `}}`))
+			if !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestConfigConnect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"mcpServers": {"test": {
+		"command": "${MCP_TEST_BINARY}",
+		"args": ["-test.run=TestConfigHelperServer"],
+		"env": {"MCP_CONFIG_HELPER_SERVER": "1", "GORACE": "atexit_sleep_ms=0"}
+	}}}`), 0o644)
+	if err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	t.Setenv("MCP_TEST_BINARY", os.Args[0])
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, err := config.Connect(ctx, "test", nil, nil)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	response, err := client.ToolCall(ctx, "test_tool", nil)
+	if err != nil || response.Content[0].Text != "Tool executed successfully" {
+		t.Errorf("Unexpected response: %+v, %v", response, err)
+	}
+	client.Close()
+	select {
+	case <-client.Done():
+	case <-ctx.Done():
+		t.Error("Timeout waiting for the client to close")
+	}
+
+	if _, err := config.Connect(ctx, "unknown", nil, nil); !errors.Is(err, ErrUnknownServer) {
+		t.Errorf("Expected ErrUnknownServer, got %v", err)
+	}
+	servers := config.HostServers()
+	if len(servers) != 1 || servers[0].Name != "test" || servers[0].Dial == nil {
+		t.Errorf("Unexpected host servers: %+v", servers)
+	}
+}
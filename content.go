@@ -0,0 +1,196 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	ContentTypeText     = "text"
+	ContentTypeImage    = "image"
+	ContentTypeAudio    = "audio"
+	ContentTypeResource = "resource"
+	// ContentTypeResourceLink is a link to a resource, to be read with
+	// resources/read, as returned by tools instead of embedding the resource.
+	ContentTypeResourceLink = "resource_link"
+)
+
+var ErrInvalidContent = errors.New("mcp: invalid content")
+
+// Content is the content of prompt messages, tool results and sampling
+// messages.
+//
+// Text Content
+//
+//	{
+//	  "type": "text",
+//	  "text": "Tool result text"
+//	}
+//
+// Image Content
+//
+//	{
+//		"type": "image",
+//		"data": "base64-encoded-data",
+//		"mimeType": "image/png"
+//	}
+//
+// Audio Content
+//
+//	{
+//	  "type": "audio",
+//	  "data": "base64-encoded-audio-data",
+//	  "mimeType": "audio/wav"
+//	}
+//
+// EmbeddedResource
+//
+//	{
+//		"type": "resource",
+//		"resource": {
+//		  "uri": "resource://example",
+//		  "mimeType": "text/plain",
+//		  "text": "Resource content"
+//		}
+//	}
+//
+// Resource Link
+//
+//	{
+//		"type": "resource_link",
+//		"uri": "file:///report.pdf",
+//		"name": "report.pdf",
+//		"mimeType": "application/pdf"
+//	}
+type Content struct {
+	Type        string                `json:"type"` // text | image | audio | resource | resource_link
+	Text        string                `json:"text,omitempty"`
+	Data        string                `json:"data,omitempty"` // base64 encoded image | audio data
+	MimeType    string                `json:"mimeType,omitempty"`
+	Resource    *ResourceContentUnion `json:"resource,omitempty"`
+	Link        *ResourceSpec         `json:"-"` // fields of a resource_link, inlined
+	Annotations *Annotations          `json:"annotations,omitempty"`
+}
+
+// Deprecated: use Content.
+type ContentTextOnly = Content
+
+// Deprecated: use Content.
+type ToolCallContentUnion = Content
+
+// Deprecated: use Content.
+type SamplingMessageContent = Content
+
+func TextContent(text string) Content {
+	return Content{Type: ContentTypeText, Text: text}
+}
+
+// ImageContent returns image content holding data, which is base64 encoded.
+func ImageContent(data []byte, mimeType string) Content {
+	return Content{Type: ContentTypeImage, Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// AudioContent returns audio content holding data, which is base64 encoded.
+func AudioContent(data []byte, mimeType string) Content {
+	return Content{Type: ContentTypeAudio, Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// ResourceContent returns content embedding resource.
+func ResourceContent(resource ResourceContentUnion) Content {
+	return Content{Type: ContentTypeResource, Resource: &resource}
+}
+
+// ResourceLinkContent returns a link to resource.
+func ResourceLinkContent(resource ResourceSpec) Content {
+	return Content{Type: ContentTypeResourceLink, Link: &resource}
+}
+
+// Validate reports, wrapping [ErrInvalidContent], content missing the fields
+// required by its type.
+func (c Content) Validate() error {
+	switch c.Type {
+	case ContentTypeText:
+		return nil
+	case ContentTypeImage, ContentTypeAudio:
+		if c.Data == "" || c.MimeType == "" {
+			return fmt.Errorf("%w: %s requires data and mimeType", ErrInvalidContent, c.Type)
+		}
+		if _, err := base64.StdEncoding.DecodeString(c.Data); err != nil {
+			return fmt.Errorf("%w: %s data is not base64 encoded", ErrInvalidContent, c.Type)
+		}
+		return nil
+	case ContentTypeResource:
+		if c.Resource == nil || c.Resource.URI == "" {
+			return fmt.Errorf("%w: resource requires a resource with an uri", ErrInvalidContent)
+		}
+		if c.Resource.Text != "" && c.Resource.Blob != "" {
+			return fmt.Errorf("%w: resource has both text and blob", ErrInvalidContent)
+		}
+		return nil
+	case ContentTypeResourceLink:
+		if c.Link == nil || c.Link.URI == "" || c.Link.Name == "" {
+			return fmt.Errorf("%w: resource_link requires an uri and a name", ErrInvalidContent)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidContent, c.Type)
+	}
+}
+
+// MarshalJSON encodes the fields relevant to the type of the content only.
+func (c Content) MarshalJSON() ([]byte, error) {
+	switch c.Type {
+	case ContentTypeText:
+		return json.Marshal(struct {
+			Type        string       `json:"type"`
+			Text        string       `json:"text"`
+			Annotations *Annotations `json:"annotations,omitempty"`
+		}{c.Type, c.Text, c.Annotations})
+	case ContentTypeImage, ContentTypeAudio:
+		return json.Marshal(struct {
+			Type        string       `json:"type"`
+			Data        string       `json:"data"`
+			MimeType    string       `json:"mimeType"`
+			Annotations *Annotations `json:"annotations,omitempty"`
+		}{c.Type, c.Data, c.MimeType, c.Annotations})
+	case ContentTypeResource:
+		return json.Marshal(struct {
+			Type        string                `json:"type"`
+			Resource    *ResourceContentUnion `json:"resource"`
+			Annotations *Annotations          `json:"annotations,omitempty"`
+		}{c.Type, c.Resource, c.Annotations})
+	case ContentTypeResourceLink:
+		var link ResourceSpec
+		if c.Link != nil {
+			link = *c.Link
+		}
+		return json.Marshal(struct {
+			Type string `json:"type"`
+			ResourceSpec
+		}{c.Type, link})
+	}
+	type content Content
+	return json.Marshal(content(c))
+}
+
+// UnmarshalJSON decodes content, gathering the fields of a resource_link in
+// Link.
+func (c *Content) UnmarshalJSON(data []byte) error {
+	type content Content
+	var decoded content
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Type != ContentTypeResourceLink {
+		*c = Content(decoded)
+		return nil
+	}
+	link := new(ResourceSpec)
+	if err := json.Unmarshal(data, link); err != nil {
+		return err
+	}
+	*c = Content{Type: decoded.Type, Link: link}
+	return nil
+}
@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestContent(t *testing.T) {
+	t.Run("MarshalJSON", func(t *testing.T) {
+		tests := []struct {
+			content Content
+			want    string
+		}{
+			{TextContent(""), `{"type":"text","text":""}`},
+			{TextContent("hello"), `{"type":"text","text":"hello"}`},
+			{ImageContent([]byte("png"), "image/png"), `{"type":"image","data":"cG5n","mimeType":"image/png"}`},
+			{AudioContent([]byte("wav"), "audio/wav"), `{"type":"audio","data":"d2F2","mimeType":"audio/wav"}`},
+			{
+				ResourceContent(ResourceContentUnion{URI: "file:///a.txt", MimeType: "text/plain", Text: "a"}),
+				`{"type":"resource","resource":{"uri":"file:///a.txt","mimeType":"text/plain","text":"a"}}`,
+			},
+			{Content{Type: "text", Text: "t", Data: "ignored"}, `{"type":"text","text":"t"}`},
+		}
+		for _, tt := range tests {
+			data, err := json.Marshal(tt.content)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, data)
+			}
+			var decoded Content
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if data2, _ := json.Marshal(decoded); string(data2) != tt.want {
+				t.Errorf("Round trip: expected %s, got %s", tt.want, data2)
+			}
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		valid := []Content{
+			TextContent(""),
+			ImageContent([]byte{1, 2}, "image/png"),
+			AudioContent([]byte{1, 2}, "audio/wav"),
+			ResourceContent(ResourceContentUnion{URI: "file:///a", Blob: "AQI="}),
+		}
+		for _, c := range valid {
+			if err := c.Validate(); err != nil {
+				t.Errorf("Expected %+v to be valid, got %v", c, err)
+			}
+		}
+		invalid := []Content{
+			{},
+			{Type: "video"},
+			{Type: ContentTypeImage, Data: "AQI="},
+			{Type: ContentTypeAudio, MimeType: "audio/wav"},
+			{Type: ContentTypeImage, Data: "not base64!", MimeType: "image/png"},
+			{Type: ContentTypeResource},
+			ResourceContent(ResourceContentUnion{URI: "file:///a", Text: "a", Blob: "AQI="}),
+		}
+		for _, c := range invalid {
+			if err := c.Validate(); !errors.Is(err, ErrInvalidContent) {
+				t.Errorf("Expected %+v to be invalid, got %v", c, err)
+			}
+		}
+	})
+
+	t.Run("PromptMessages", func(t *testing.T) {
+		response := PromptGetResponse{Messages: []MessageWithRole{
+			{Role: "user", Content: TextContent("What is in this image?")},
+			{Role: "user", Content: ImageContent([]byte("png"), "image/png")},
+		}}
+		data, err := json.Marshal(response)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		want := `{"description":"","messages":[{"role":"user","content":{"type":"text","text":"What is in this image?"}},` +
+			`{"role":"user","content":{"type":"image","data":"cG5n","mimeType":"image/png"}}]}`
+		if string(data) != want {
+			t.Errorf("Expected %s, got %s", want, data)
+		}
+	})
+}
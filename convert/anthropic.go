@@ -0,0 +1,124 @@
+package convert
+
+import "github.com/vibeus/mcp"
+
+// anthropicImageTypes are the media types of the images accepted by the
+// Anthropic API.
+var anthropicImageTypes = map[string]bool{
+	"image/jpeg": true, "image/png": true, "image/gif": true, "image/webp": true,
+}
+
+// AnthropicTool is the definition of a tool in the Anthropic Messages API.
+type AnthropicTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema Schema `json:"input_schema"`
+}
+
+// AnthropicToolUse is a tool_use content block, a tool call of the model.
+type AnthropicToolUse struct {
+	Type  string         `json:"type"` // tool_use
+	ID    string         `json:"id"`
+	Name  string         `json:"name"`
+	Input map[string]any `json:"input"`
+}
+
+// AnthropicToolResult is a tool_result content block, the result of a
+// tool_use.
+type AnthropicToolResult struct {
+	Type      string             `json:"type"` // tool_result
+	ToolUseID string             `json:"tool_use_id"`
+	Content   []AnthropicContent `json:"content"`
+	IsError   bool               `json:"is_error,omitempty"`
+}
+
+// AnthropicContent is a text, image or document content block.
+type AnthropicContent struct {
+	Type   string           `json:"type"` // text | image | document
+	Text   string           `json:"text,omitempty"`
+	Source *AnthropicSource `json:"source,omitempty"`
+	Title  string           `json:"title,omitempty"`
+}
+
+type AnthropicSource struct {
+	Type      string `json:"type"` // base64 | text
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+// Anthropic converts tools to and from the Anthropic Messages API.
+type Anthropic struct {
+	names names
+}
+
+func NewAnthropic() *Anthropic {
+	return &Anthropic{names: newNames(func(r rune, first bool) bool {
+		return isAlnum(r) || r == '_' || r == '-'
+	})}
+}
+
+// Tools returns the definitions of the tools of specs.
+func (c *Anthropic) Tools(specs []mcp.ToolSpec) []AnthropicTool {
+	tools := make([]AnthropicTool, 0, len(specs))
+	for _, spec := range specs {
+		tools = append(tools, AnthropicTool{
+			Name:        c.names.model(spec.Name),
+			Description: spec.Description,
+			InputSchema: toSchema(spec.InputSchema),
+		})
+	}
+	return tools
+}
+
+// ToolCall returns the MCP call of use.
+func (c *Anthropic) ToolCall(use AnthropicToolUse) (mcp.ToolCallRequest, error) {
+	args, err := toArguments(use.Input)
+	if err != nil {
+		return mcp.ToolCallRequest{}, err
+	}
+	return mcp.ToolCallRequest{Name: c.names.tool(use.Name), Arguments: args}, nil
+}
+
+// ToolResult returns the tool_result of the tool_use identified by toolUseID.
+// Images and PDF documents are passed as such, text resources as documents,
+// and content the API does not take is described in text.
+func (c *Anthropic) ToolResult(toolUseID string, res mcp.ToolCallResponse) AnthropicToolResult {
+	result := AnthropicToolResult{
+		Type:      "tool_result",
+		ToolUseID: toolUseID,
+		Content:   make([]AnthropicContent, 0, len(res.Content)),
+		IsError:   res.IsError,
+	}
+	for _, content := range res.Content {
+		result.Content = append(result.Content, anthropicContent(content))
+	}
+	return result
+}
+
+func anthropicContent(content mcp.Content) AnthropicContent {
+	text := func(s string) AnthropicContent {
+		return AnthropicContent{Type: "text", Text: s}
+	}
+	switch content.Type {
+	case mcp.ContentTypeText:
+		return text(content.Text)
+	case mcp.ContentTypeImage:
+		if anthropicImageTypes[content.MimeType] {
+			return AnthropicContent{Type: "image", Source: &AnthropicSource{Type: "base64", MediaType: content.MimeType, Data: content.Data}}
+		}
+	case mcp.ContentTypeResource:
+		resource := content.Resource
+		if resource == nil {
+			break
+		}
+		switch {
+		case resource.Blob == "" && resource.MimeType != "application/pdf":
+			return AnthropicContent{Type: "document", Source: &AnthropicSource{Type: "text", MediaType: "text/plain", Data: resource.Text}, Title: resource.URI}
+		case anthropicImageTypes[resource.MimeType]:
+			return AnthropicContent{Type: "image", Source: &AnthropicSource{Type: "base64", MediaType: resource.MimeType, Data: resource.Blob}}
+		case resource.MimeType == "application/pdf" && resource.Blob != "":
+			return AnthropicContent{Type: "document", Source: &AnthropicSource{Type: "base64", MediaType: resource.MimeType, Data: resource.Blob}, Title: resource.URI}
+		}
+	}
+	return text(describe(content))
+}
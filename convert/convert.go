@@ -0,0 +1,180 @@
+// Package convert translates the tools of MCP servers to and from the
+// function calling formats of model APIs: tool definitions, the tool calls
+// requested by models and the results given back to them.
+//
+// Each API has a converter remembering the names given to tools, as tool names
+// are sanitized to the characters accepted by the API. Tool calls use the
+// names of the converter, and are mapped back to the names of the tools.
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vibeus/mcp"
+)
+
+// MaxNameLength is the maximum length of tool names accepted by model APIs.
+const MaxNameLength = 64
+
+var ErrInvalidArguments = errors.New("convert: invalid tool call arguments")
+
+// names maps the names of tools onto the names accepted by an API, and back.
+type names struct {
+	// valid reports whether r is accepted in names, first telling whether r
+	// would be the first rune.
+	valid   func(r rune, first bool) bool
+	toModel map[string]string
+	toTool  map[string]string
+}
+
+func newNames(valid func(r rune, first bool) bool) names {
+	return names{valid: valid, toModel: make(map[string]string), toTool: make(map[string]string)}
+}
+
+// model returns the name of the tool name for the API. Invalid runes are
+// replaced by underscores and long names are truncated, with a numeric suffix
+// added to names already given to another tool.
+func (n *names) model(name string) string {
+	if sanitized, ok := n.toModel[name]; ok {
+		return sanitized
+	}
+	var b strings.Builder
+	for i, r := range name {
+		if !n.valid(r, i == 0) {
+			if i == 0 && n.valid('_', true) && n.valid(r, false) {
+				b.WriteRune('_')
+				b.WriteRune(r)
+				continue
+			}
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	base := b.String()
+	if base == "" {
+		base = "tool"
+	}
+	sanitized := truncate(base, MaxNameLength)
+	for i := 2; ; i++ {
+		if _, taken := n.toTool[sanitized]; !taken {
+			break
+		}
+		suffix := fmt.Sprintf("_%d", i)
+		sanitized = truncate(base, MaxNameLength-len(suffix)) + suffix
+	}
+	n.toModel[name] = sanitized
+	n.toTool[sanitized] = name
+	return sanitized
+}
+
+// tool returns the name of the tool named name by the API. Unknown names are
+// returned unchanged.
+func (n *names) tool(name string) string {
+	if original, ok := n.toTool[name]; ok {
+		return original
+	}
+	return name
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+func isAlnum(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// Schema is the JSON Schema of the parameters of a tool, or of one of its
+// parameters, as accepted by the Anthropic and OpenAI APIs.
+type Schema struct {
+	Type        string            `json:"type"`
+	Description string            `json:"description,omitempty"`
+	Properties  map[string]Schema `json:"properties,omitempty"`
+	Required    []string          `json:"required,omitempty"`
+	Items       *Schema           `json:"items,omitempty"`
+	Enum        []string          `json:"enum,omitempty"`
+}
+
+// jsonSchemaTypes are the types of parameters kept by down-conversion.
+var jsonSchemaTypes = map[string]bool{
+	"string": true, "number": true, "integer": true, "boolean": true, "array": true, "object": true,
+}
+
+// toSchema down-converts schema to the parameters of a tool. Parameters of
+// unknown types are strings, which is how MCP passes all arguments, arrays
+// without item types hold strings, enumerations are kept for strings only, and
+// required parameters not described are dropped.
+func toSchema(schema mcp.ToolSchema) Schema {
+	converted := Schema{Type: "object", Properties: make(map[string]Schema)}
+	for name, param := range schema.Properties {
+		converted.Properties[name] = toParamSchema(param)
+	}
+	for _, name := range schema.Required {
+		if _, ok := schema.Properties[name]; ok {
+			converted.Required = append(converted.Required, name)
+		}
+	}
+	return converted
+}
+
+func toParamSchema(param mcp.ParamSchema) Schema {
+	converted := Schema{Type: param.Type, Description: param.Description}
+	if !jsonSchemaTypes[converted.Type] {
+		converted.Type = "string"
+	}
+	if converted.Type == "array" {
+		converted.Items = &Schema{Type: "string"}
+	}
+	if converted.Type == "string" {
+		converted.Enum = param.Enum
+	}
+	return converted
+}
+
+// toArguments converts the arguments of a tool call of a model to MCP
+// arguments, encoding values other than strings as JSON.
+func toArguments(input map[string]any) (map[string]string, error) {
+	args := make(map[string]string, len(input))
+	for name, value := range input {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			args[name] = v
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidArguments, name, err)
+			}
+			args[name] = string(data)
+		}
+	}
+	return args, nil
+}
+
+// describe returns a text standing for content that an API cannot take.
+func describe(content mcp.Content) string {
+	switch content.Type {
+	case mcp.ContentTypeImage, mcp.ContentTypeAudio:
+		return fmt.Sprintf("[%s content of type %s omitted]", content.Type, content.MimeType)
+	case mcp.ContentTypeResource:
+		if content.Resource != nil {
+			return fmt.Sprintf("[resource %s of type %s omitted]", content.Resource.URI, content.Resource.MimeType)
+		}
+	case mcp.ContentTypeResourceLink:
+		if content.Link != nil {
+			return fmt.Sprintf("[resource %s: %s]", content.Link.Name, content.Link.URI)
+		}
+	}
+	return fmt.Sprintf("[%s content omitted]", content.Type)
+}
+
+// resourceText returns the text of a text resource, headed by its URI.
+func resourceText(resource *mcp.ResourceContentUnion) string {
+	return fmt.Sprintf("[resource %s]\n%s", resource.URI, resource.Text)
+}
@@ -0,0 +1,174 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vibeus/mcp"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// testTools have names that need sanitizing and parameters that need
+// down-converting.
+var testTools = []mcp.ToolSpec{
+	{
+		Name:        "files/read",
+		Description: "Read a file",
+		InputSchema: mcp.ToolSchema{
+			Type: "object",
+			Properties: map[string]mcp.ParamSchema{
+				"path":   {Type: "string", Description: "Path of the file"},
+				"lines":  {Type: "array", Description: "Lines to read"},
+				"offset": {Type: "integer"},
+				"filter": {Type: "object", Description: "Filter of the lines"},
+				"mode":   {Type: "string|null"},
+				"unit":   {Type: "string", Enum: []string{"lines", "bytes"}},
+			},
+			Required: []string{"path", "missing"},
+		},
+	},
+	{Name: "files_read", Description: "Taken name"},
+	{Name: "2fa code", Description: "Invalid first character for Gemini"},
+	{Name: "search.web:" + strings.Repeat("x", 70)},
+}
+
+var testResponse = mcp.ToolCallResponse{
+	Content: []mcp.Content{
+		mcp.TextContent("Read 2 lines"),
+		mcp.ImageContent([]byte("png"), "image/png"),
+		mcp.ImageContent([]byte("bmp"), "image/bmp"),
+		mcp.AudioContent([]byte("wav"), "audio/wav"),
+		mcp.ResourceContent(mcp.ResourceContentUnion{URI: "file:///notes.txt", MimeType: "text/plain", Text: "first\nsecond"}),
+		mcp.ResourceContent(mcp.ResourceContentUnion{URI: "file:///report.pdf", MimeType: "application/pdf", Blob: base64.StdEncoding.EncodeToString([]byte("%PDF"))}),
+		mcp.ResourceLinkContent(mcp.ResourceSpec{URI: "file:///big.csv", Name: "big.csv"}),
+	},
+}
+
+func golden(t *testing.T, name string, v any) {
+	t.Helper()
+	got, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	got = append(got, '\n')
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from golden file:\n%s", name, got)
+	}
+}
+
+func TestAnthropic(t *testing.T) {
+	c := NewAnthropic()
+	golden(t, "anthropic_tools", c.Tools(testTools))
+	golden(t, "anthropic_result", c.ToolResult("toolu_01", testResponse))
+	golden(t, "anthropic_error", c.ToolResult("toolu_02", mcp.ToolCallResponse{
+		Content: []mcp.Content{mcp.TextContent("no such file")},
+		IsError: true,
+	}))
+
+	call, err := c.ToolCall(AnthropicToolUse{
+		Type:  "tool_use",
+		ID:    "toolu_01",
+		Name:  "files_read",
+		Input: map[string]any{"path": "/a", "offset": 10.0, "lines": []any{1.0, 2.0}, "mode": nil},
+	})
+	if err != nil {
+		t.Fatalf("ToolCall failed: %v", err)
+	}
+	want := mcp.ToolCallRequest{Name: "files/read", Arguments: map[string]string{"path": "/a", "offset": "10", "lines": "[1,2]"}}
+	if !reflect.DeepEqual(call, want) {
+		t.Errorf("Expected %+v, got %+v", want, call)
+	}
+	if call, _ := c.ToolCall(AnthropicToolUse{Name: "files_read_2"}); call.Name != "files_read" {
+		t.Errorf("Expected files_read, got %s", call.Name)
+	}
+}
+
+func TestOpenAI(t *testing.T) {
+	c := NewOpenAI()
+	golden(t, "openai_tools", c.Tools(testTools))
+	golden(t, "openai_result", c.ToolResult("call_01", testResponse))
+	golden(t, "openai_error", c.ToolResult("call_02", mcp.ToolCallResponse{
+		Content: []mcp.Content{mcp.TextContent("no such file")},
+		IsError: true,
+	}))
+
+	call, err := c.ToolCall(OpenAIToolCall{
+		ID:       "call_01",
+		Type:     "function",
+		Function: OpenAIFunctionCall{Name: "files_read", Arguments: `{"path":"/a","offset":10}`},
+	})
+	if err != nil {
+		t.Fatalf("ToolCall failed: %v", err)
+	}
+	want := mcp.ToolCallRequest{Name: "files/read", Arguments: map[string]string{"path": "/a", "offset": "10"}}
+	if !reflect.DeepEqual(call, want) {
+		t.Errorf("Expected %+v, got %+v", want, call)
+	}
+	if _, err := c.ToolCall(OpenAIToolCall{Function: OpenAIFunctionCall{Name: "files_read", Arguments: `[1]`}}); !errors.Is(err, ErrInvalidArguments) {
+		t.Errorf("Expected ErrInvalidArguments, got %v", err)
+	}
+}
+
+func TestGemini(t *testing.T) {
+	c := NewGemini()
+	golden(t, "gemini_tools", c.Tools(testTools))
+	golden(t, "gemini_result", c.ToolResult("files/read", testResponse))
+	golden(t, "gemini_error", c.ToolResult("files/read", mcp.ToolCallResponse{
+		Content: []mcp.Content{mcp.TextContent("no such file")},
+		IsError: true,
+	}))
+
+	call, err := c.ToolCall(GeminiFunctionCall{Name: "_2fa_code", Args: map[string]any{"verbose": true}})
+	if err != nil {
+		t.Fatalf("ToolCall failed: %v", err)
+	}
+	want := mcp.ToolCallRequest{Name: "2fa code", Arguments: map[string]string{"verbose": "true"}}
+	if !reflect.DeepEqual(call, want) {
+		t.Errorf("Expected %+v, got %+v", want, call)
+	}
+}
+
+func TestNames(t *testing.T) {
+	n := NewAnthropic().names
+	tests := []struct{ name, want string }{
+		{"read", "read"},
+		{"files/read", "files_read"},
+		{"files_read", "files_read_2"},
+		{"files:read", "files_read_3"},
+		{"files/read", "files_read"},
+		{"", "tool"},
+		{strings.Repeat("a", 70), strings.Repeat("a", 64)},
+		{strings.Repeat("a", 65), strings.Repeat("a", 62) + "_2"},
+	}
+	for _, tt := range tests {
+		if got := n.model(tt.name); got != tt.want {
+			t.Errorf("Expected %q for %q, got %q", tt.want, tt.name, got)
+		}
+		if got := n.tool(tt.want); got != tt.name && tt.name != "files/read" {
+			t.Errorf("Expected %q back from %q, got %q", tt.name, tt.want, got)
+		}
+	}
+	if got := n.tool("unknown"); got != "unknown" {
+		t.Errorf("Expected unknown names unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,151 @@
+package convert
+
+import (
+	"strings"
+
+	"github.com/vibeus/mcp"
+)
+
+// GeminiFunctionDeclaration is the definition of a function in the Gemini
+// API.
+type GeminiFunctionDeclaration struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Parameters  *GeminiSchema `json:"parameters,omitempty"`
+}
+
+// GeminiSchema is the subset of the OpenAPI schema taken by the Gemini API.
+type GeminiSchema struct {
+	Type        string                   `json:"type"` // STRING | NUMBER | INTEGER | BOOLEAN | ARRAY | OBJECT
+	Description string                   `json:"description,omitempty"`
+	Properties  map[string]*GeminiSchema `json:"properties,omitempty"`
+	Required    []string                 `json:"required,omitempty"`
+	Items       *GeminiSchema            `json:"items,omitempty"`
+	Format      string                   `json:"format,omitempty"`
+	Enum        []string                 `json:"enum,omitempty"`
+}
+
+// GeminiFunctionCall is a function call of the model.
+type GeminiFunctionCall struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+}
+
+// GeminiFunctionResponse is the result of a function call.
+type GeminiFunctionResponse struct {
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+// GeminiPart is a part of the content of a message, limited to the fields
+// used for tool results.
+type GeminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	InlineData       *GeminiBlob             `json:"inlineData,omitempty"`
+	FunctionResponse *GeminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type GeminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"` // base64 encoded
+}
+
+// Gemini converts tools to and from the Gemini API.
+type Gemini struct {
+	names names
+}
+
+func NewGemini() *Gemini {
+	return &Gemini{names: newNames(func(r rune, first bool) bool {
+		if first {
+			return r == '_' || isAlnum(r) && (r < '0' || r > '9')
+		}
+		return isAlnum(r) || r == '_' || r == '-' || r == '.' || r == ':'
+	})}
+}
+
+// Tools returns the declarations of the functions of specs. Tools without
+// parameters are declared without a schema, which Gemini requires.
+func (c *Gemini) Tools(specs []mcp.ToolSpec) []GeminiFunctionDeclaration {
+	declarations := make([]GeminiFunctionDeclaration, 0, len(specs))
+	for _, spec := range specs {
+		declaration := GeminiFunctionDeclaration{
+			Name:        c.names.model(spec.Name),
+			Description: spec.Description,
+		}
+		if len(spec.InputSchema.Properties) > 0 {
+			declaration.Parameters = toGeminiSchema(toSchema(spec.InputSchema))
+		}
+		declarations = append(declarations, declaration)
+	}
+	return declarations
+}
+
+// toGeminiSchema down-converts schema, which Gemini takes with upper case
+// types. Objects without properties are not accepted, so they are passed as
+// JSON encoded strings.
+func toGeminiSchema(schema Schema) *GeminiSchema {
+	converted := &GeminiSchema{
+		Type:        strings.ToUpper(schema.Type),
+		Description: schema.Description,
+		Required:    schema.Required,
+	}
+	if len(schema.Enum) > 0 {
+		converted.Format, converted.Enum = "enum", schema.Enum
+	}
+	if schema.Type == "object" && len(schema.Properties) == 0 {
+		converted.Type = "STRING"
+		converted.Description = strings.TrimSpace(converted.Description + " (JSON encoded object)")
+	}
+	if len(schema.Properties) > 0 {
+		converted.Properties = make(map[string]*GeminiSchema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			converted.Properties[name] = toGeminiSchema(property)
+		}
+	}
+	if schema.Items != nil {
+		converted.Items = toGeminiSchema(*schema.Items)
+	}
+	return converted
+}
+
+// ToolCall returns the MCP call of call.
+func (c *Gemini) ToolCall(call GeminiFunctionCall) (mcp.ToolCallRequest, error) {
+	args, err := toArguments(call.Args)
+	if err != nil {
+		return mcp.ToolCallRequest{}, err
+	}
+	return mcp.ToolCallRequest{Name: c.names.tool(call.Name), Arguments: args}, nil
+}
+
+// ToolResult returns the parts giving res, the result of a call of the tool
+// named name, to the model. The text of res is the content, or the error, of
+// the function response, followed by images and audio as inline data.
+func (c *Gemini) ToolResult(name string, res mcp.ToolCallResponse) []GeminiPart {
+	var texts []string
+	var media []GeminiPart
+	for _, content := range res.Content {
+		switch {
+		case content.Type == mcp.ContentTypeText:
+			texts = append(texts, content.Text)
+		case content.Type == mcp.ContentTypeImage || content.Type == mcp.ContentTypeAudio:
+			media = append(media, GeminiPart{InlineData: &GeminiBlob{MimeType: content.MimeType, Data: content.Data}})
+		case content.Type == mcp.ContentTypeResource && content.Resource != nil && content.Resource.Blob == "":
+			texts = append(texts, resourceText(content.Resource))
+		case content.Type == mcp.ContentTypeResource && content.Resource != nil && content.Resource.MimeType != "":
+			media = append(media, GeminiPart{InlineData: &GeminiBlob{MimeType: content.Resource.MimeType, Data: content.Resource.Blob}})
+		default:
+			texts = append(texts, describe(content))
+		}
+	}
+
+	key := "content"
+	if res.IsError {
+		key = "error"
+	}
+	parts := []GeminiPart{{FunctionResponse: &GeminiFunctionResponse{
+		Name:     c.names.model(name),
+		Response: map[string]any{key: strings.Join(texts, "\n")},
+	}}}
+	return append(parts, media...)
+}
@@ -0,0 +1,156 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/vibeus/mcp"
+)
+
+// openAIAudioFormats maps the media types of the audio accepted by the OpenAI
+// API onto their formats.
+var openAIAudioFormats = map[string]string{
+	"audio/wav": "wav", "audio/x-wav": "wav", "audio/mpeg": "mp3", "audio/mp3": "mp3",
+}
+
+// OpenAITool is the definition of a function in the OpenAI Chat Completions
+// API.
+type OpenAITool struct {
+	Type     string         `json:"type"` // function
+	Function OpenAIFunction `json:"function"`
+}
+
+type OpenAIFunction struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  Schema `json:"parameters"`
+}
+
+// OpenAIToolCall is a function call of the model.
+type OpenAIToolCall struct {
+	ID       string             `json:"id"`
+	Type     string             `json:"type"` // function
+	Function OpenAIFunctionCall `json:"function"`
+}
+
+type OpenAIFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON encoded object
+}
+
+// OpenAIMessage is a message of a chat, limited to the fields used for tool
+// results.
+type OpenAIMessage struct {
+	Role       string              `json:"role"` // tool | user
+	ToolCallID string              `json:"tool_call_id,omitempty"`
+	Content    []OpenAIContentPart `json:"content"`
+}
+
+// OpenAIContentPart is a text, image_url or input_audio part of the content
+// of a message.
+type OpenAIContentPart struct {
+	Type       string            `json:"type"`
+	Text       string            `json:"text,omitempty"`
+	ImageURL   *OpenAIImageURL   `json:"image_url,omitempty"`
+	InputAudio *OpenAIInputAudio `json:"input_audio,omitempty"`
+}
+
+type OpenAIImageURL struct {
+	URL string `json:"url"`
+}
+
+type OpenAIInputAudio struct {
+	Data   string `json:"data"`
+	Format string `json:"format"` // wav | mp3
+}
+
+// OpenAI converts tools to and from the OpenAI Chat Completions API.
+type OpenAI struct {
+	names names
+}
+
+func NewOpenAI() *OpenAI {
+	return &OpenAI{names: newNames(func(r rune, first bool) bool {
+		return isAlnum(r) || r == '_' || r == '-'
+	})}
+}
+
+// Tools returns the definitions of the tools of specs.
+func (c *OpenAI) Tools(specs []mcp.ToolSpec) []OpenAITool {
+	tools := make([]OpenAITool, 0, len(specs))
+	for _, spec := range specs {
+		tools = append(tools, OpenAITool{
+			Type: "function",
+			Function: OpenAIFunction{
+				Name:        c.names.model(spec.Name),
+				Description: spec.Description,
+				Parameters:  toSchema(spec.InputSchema),
+			},
+		})
+	}
+	return tools
+}
+
+// ToolCall returns the MCP call of call.
+func (c *OpenAI) ToolCall(call OpenAIToolCall) (mcp.ToolCallRequest, error) {
+	var input map[string]any
+	if strings.TrimSpace(call.Function.Arguments) != "" {
+		if err := json.Unmarshal([]byte(call.Function.Arguments), &input); err != nil {
+			return mcp.ToolCallRequest{}, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+		}
+	}
+	args, err := toArguments(input)
+	if err != nil {
+		return mcp.ToolCallRequest{}, err
+	}
+	return mcp.ToolCallRequest{Name: c.names.tool(call.Function.Name), Arguments: args}, nil
+}
+
+// ToolResult returns the messages giving res, the result of the call
+// identified by toolCallID, to the model. Tool messages only take text, so the
+// images and audio of res follow in a user message. Failed calls are reported
+// in a text part, the API having no error flag.
+func (c *OpenAI) ToolResult(toolCallID string, res mcp.ToolCallResponse) []OpenAIMessage {
+	tool := OpenAIMessage{Role: "tool", ToolCallID: toolCallID, Content: []OpenAIContentPart{}}
+	media := OpenAIMessage{Role: "user"}
+	text := func(s string) {
+		tool.Content = append(tool.Content, OpenAIContentPart{Type: "text", Text: s})
+	}
+	image := func(mimeType, data string) {
+		text(fmt.Sprintf("[image %d follows]", len(media.Content)+1))
+		media.Content = append(media.Content, OpenAIContentPart{
+			Type:     "image_url",
+			ImageURL: &OpenAIImageURL{URL: "data:" + mimeType + ";base64," + data},
+		})
+	}
+
+	if res.IsError {
+		text("The tool call failed.")
+	}
+	for _, content := range res.Content {
+		switch {
+		case content.Type == mcp.ContentTypeText:
+			text(content.Text)
+		case content.Type == mcp.ContentTypeImage:
+			image(content.MimeType, content.Data)
+		case content.Type == mcp.ContentTypeAudio && openAIAudioFormats[content.MimeType] != "":
+			text(fmt.Sprintf("[audio %d follows]", len(media.Content)+1))
+			media.Content = append(media.Content, OpenAIContentPart{
+				Type:       "input_audio",
+				InputAudio: &OpenAIInputAudio{Data: content.Data, Format: openAIAudioFormats[content.MimeType]},
+			})
+		case content.Type == mcp.ContentTypeResource && content.Resource != nil && content.Resource.Blob == "":
+			text(resourceText(content.Resource))
+		case content.Type == mcp.ContentTypeResource && content.Resource != nil && strings.HasPrefix(content.Resource.MimeType, "image/"):
+			image(content.Resource.MimeType, content.Resource.Blob)
+		default:
+			text(describe(content))
+		}
+	}
+
+	if len(media.Content) == 0 {
+		return []OpenAIMessage{tool}
+	}
+	return []OpenAIMessage{tool, media}
+}
@@ -54,16 +54,24 @@ func (c *testServerImpl) Prompts_OnList(cursor string) []ListPromptsResponse {
 	}
 }
 func (c *testServerImpl) Prompts_ListChanged() chan struct{} { return c.prompts_ListChanged }
-func (c *testServerImpl) Prompts_OnGet(name string) (PromptGetResponse, *jsonrpc2.ErrorObject) {
+func (c *testServerImpl) Prompts_Find(name string) (PromptSpec, bool) {
+	for _, spec := range c.Prompts_OnList("")[0].Prompts {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return PromptSpec{}, false
+}
+func (c *testServerImpl) Prompts_OnGet(name string, args map[string]string) (PromptGetResponse, *jsonrpc2.ErrorObject) {
 	if name == "test_prompt" {
 		return PromptGetResponse{
 			Description: "Test prompt for demonstration",
 			Messages: []MessageWithRole{
 				{
 					Role: "assistant",
-					Content: ContentTextOnly{
+					Content: Content{
 						Type: "text",
-						Text: "Question to ask",
+						Text: "Question to ask: " + args["question"],
 					},
 				},
 			},
@@ -153,7 +161,7 @@ func (c *testServerImpl) Tools_ListChanged() chan struct{} { return c.tools_List
 func (c *testServerImpl) Tools_OnCall(name string, args map[string]string) (ToolCallResponse, *jsonrpc2.ErrorObject) {
 	if name == "test_tool" {
 		return ToolCallResponse{
-			Content: []ToolCallContentUnion{
+			Content: []Content{
 				{
 					Type: "text",
 					Text: "Tool executed successfully",
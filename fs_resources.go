@@ -0,0 +1,316 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// sniffLen is the number of bytes used to detect the content type of a file,
+// as documented by [http.DetectContentType].
+const sniffLen = 512
+
+// FSResourceProvider is a [CapResourcesProvider] exposing the files of an
+// [fs.FS], such as a directory from [os.DirFS] or an [embed.FS], as resources.
+//
+// The URI of a file is BaseURI followed by its slash-separated path in the file
+// system, with each element percent-encoded. Text files are read as text
+// content and other files as base64 blobs.
+type FSResourceProvider struct {
+	fsys fs.FS
+
+	// BaseURI prefixes the paths of the files, "file:///" by default, or the
+	// file URI of the directory of a provider created with
+	// [NewDirResourceProvider].
+	BaseURI string
+	// Include lists the glob patterns, in the syntax of [path.Match], of the
+	// files to expose; all files are exposed if it is empty. A pattern without
+	// a slash is matched against the base name of a file, other patterns
+	// against its whole path.
+	Include []string
+	// Exclude lists the glob patterns of the files and directories to hide,
+	// with the same syntax as Include.
+	Exclude []string
+	// MaxFileSize hides the files larger than this size in bytes, if positive.
+	MaxFileSize int64
+	// WatchOptions configures the watching of the directory of a provider
+	// created with [NewDirResourceProvider].
+	WatchOptions WatchOptions
+
+	dir     string
+	started sync.Once
+}
+
+// NewFSResourceProvider returns a provider exposing the files of fsys, with URIs
+// prefixed by baseURI, or "file:///" if baseURI is empty.
+func NewFSResourceProvider(fsys fs.FS, baseURI string) *FSResourceProvider {
+	if baseURI == "" {
+		baseURI = "file:///"
+	}
+	return &FSResourceProvider{fsys: fsys, BaseURI: baseURI}
+}
+
+// NewDirResourceProvider returns a provider exposing the files of the directory
+// dir, like [NewFSResourceProvider], with URIs prefixed by the file URI of dir
+// if baseURI is empty. Symbolic links are neither listed nor read. The
+// directory is watched during each session: files created or removed are
+// notified as a change of the resource list, and files written as updates of
+// the subscribed resources.
+func NewDirResourceProvider(dir string, baseURI string) *FSResourceProvider {
+	if baseURI == "" {
+		baseURI = dirURI(dir)
+	}
+	p := NewFSResourceProvider(os.DirFS(dir), baseURI)
+	p.dir = dir
+	return p
+}
+
+func (p *FSResourceProvider) Resources_Started() *sync.Once {
+	return &p.started
+}
+
+func (p *FSResourceProvider) Resources_Capability() *CapResources {
+	return &CapResources{
+		ListChanged: p.dir != "",
+		Subscribe:   p.dir != "",
+	}
+}
+
+func (p *FSResourceProvider) Resources_OnList(cursor string) []ResourceSpec {
+	var resources []ResourceSpec
+	fs.WalkDir(p.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if name != "." && matchAny(p.Exclude, name) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !p.exposed(name) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() || (p.MaxFileSize > 0 && info.Size() > p.MaxFileSize) {
+			return nil
+		}
+		resources = append(resources, ResourceSpec{
+			URI:      p.URIOf(name),
+			Name:     name,
+			MimeType: p.sniffFile(name),
+			Size:     info.Size(),
+		})
+		return nil
+	})
+	return resources
+}
+
+func (p *FSResourceProvider) Resources_OnTemplatesList() []ResourceTemplateSpec {
+	return nil
+}
+
+func (p *FSResourceProvider) Resources_OnRead(uri string) []ResourceContentUnion {
+	name, ok := p.PathOf(uri)
+	if !ok || !p.exposed(name) || p.linked(name) {
+		return nil
+	}
+	f, err := p.fsys.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() || (p.MaxFileSize > 0 && info.Size() > p.MaxFileSize) {
+		return nil
+	}
+	var r io.Reader = f
+	if p.MaxFileSize > 0 {
+		r = io.LimitReader(f, p.MaxFileSize)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil
+	}
+
+	mimeType := mimeTypeOf(name, data)
+	content := ResourceContentUnion{URI: uri, MimeType: mimeType}
+	if isText(mimeType, data) {
+		content.Text = string(data)
+	} else {
+		content.Blob = base64.StdEncoding.EncodeToString(data)
+	}
+	return []ResourceContentUnion{content}
+}
+
+func (p *FSResourceProvider) Resources_ListChanged() chan struct{} {
+	return nil
+}
+
+func (p *FSResourceProvider) Resources_Watch(ctx context.Context, changes ResourceChangeReporter) {
+	if p.dir == "" {
+		return
+	}
+	err := WatchDir(ctx, p.dir, p.WatchOptions, func(events []WatchEvent) {
+		listChanged := false
+		for _, e := range events {
+			if e.Op&WatchRescan != 0 {
+				listChanged = true
+				for _, resource := range p.Resources_OnList("") {
+					changes.Updated(resource.URI)
+				}
+				continue
+			}
+			if e.IsDir {
+				if e.Op&(WatchCreate|WatchRemove) != 0 && !matchAny(p.Exclude, e.Path) {
+					listChanged = true
+				}
+				continue
+			}
+			if !p.exposed(e.Path) {
+				continue
+			}
+			if e.Op&(WatchCreate|WatchRemove) != 0 {
+				listChanged = true
+			}
+			if e.Op&(WatchWrite|WatchRemove) != 0 {
+				changes.Updated(p.URIOf(e.Path))
+			}
+		}
+		if listChanged {
+			changes.ListChanged()
+		}
+	})
+	if err != nil {
+		changes.Failed(err)
+	}
+}
+
+// URIOf returns the URI of the file at name.
+func (p *FSResourceProvider) URIOf(name string) string {
+	elems := strings.Split(name, "/")
+	for i, elem := range elems {
+		elems[i] = url.PathEscape(elem)
+	}
+	return p.BaseURI + strings.Join(elems, "/")
+}
+
+// PathOf returns the path of the file addressed by uri, and whether uri is a
+// valid URI of the provider.
+func (p *FSResourceProvider) PathOf(uri string) (string, bool) {
+	rest, ok := strings.CutPrefix(uri, p.BaseURI)
+	if !ok {
+		return "", false
+	}
+	name, err := url.PathUnescape(rest)
+	if err != nil || !fs.ValidPath(name) || name == "." {
+		return "", false
+	}
+	return name, true
+}
+
+// exposed reports whether the file at name passes the include and exclude
+// patterns, including those of its parent directories.
+func (p *FSResourceProvider) exposed(name string) bool {
+	if len(p.Include) > 0 && !matchAny(p.Include, name) {
+		return false
+	}
+	for dir := name; dir != "."; dir = path.Dir(dir) {
+		if matchAny(p.Exclude, dir) {
+			return false
+		}
+	}
+	return true
+}
+
+// linked reports whether the file at name, or one of its parent directories,
+// is a symbolic link in the directory of the provider, or cannot be checked.
+func (p *FSResourceProvider) linked(name string) bool {
+	if p.dir == "" {
+		return false
+	}
+	for elem := name; elem != "."; elem = path.Dir(elem) {
+		info, err := os.Lstat(filepath.Join(p.dir, filepath.FromSlash(elem)))
+		if err != nil || info.Mode()&fs.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// sniffFile returns the MIME type of the file at name, from its extension or
+// else from its first bytes.
+func (p *FSResourceProvider) sniffFile(name string) string {
+	if mimeType := mime.TypeByExtension(path.Ext(name)); mimeType != "" {
+		return mimeType
+	}
+	f, err := p.fsys.Open(name)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	buf := make([]byte, sniffLen)
+	n, _ := io.ReadFull(f, buf)
+	return http.DetectContentType(buf[:n])
+}
+
+// dirURI returns the file URI of the directory dir, ending with a slash.
+func dirURI(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	p := filepath.ToSlash(dir)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	u := url.URL{Scheme: "file", Path: p}
+	return strings.TrimSuffix(u.String(), "/") + "/"
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		target := name
+		if !strings.Contains(pattern, "/") {
+			target = path.Base(name)
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func mimeTypeOf(name string, data []byte) string {
+	if mimeType := mime.TypeByExtension(path.Ext(name)); mimeType != "" {
+		return mimeType
+	}
+	return http.DetectContentType(data[:min(len(data), sniffLen)])
+}
+
+// isText reports whether data of the given MIME type can be returned as text.
+func isText(mimeType string, data []byte) bool {
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	for _, suffix := range []string{"json", "xml", "javascript", "yaml", "toml"} {
+		if strings.HasSuffix(mediaType, suffix) {
+			return true
+		}
+	}
+	return false
+}
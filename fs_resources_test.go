@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSResourceProvider(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	fsys := fstest.MapFS{
+		"readme":           {Data: []byte("# Hello\n")},
+		"docs/guide.html":  {Data: []byte("<html></html>")},
+		"docs/my notes":    {Data: []byte("notes")},
+		"data/config.json": {Data: []byte(`{"a": 1}`)},
+		"img/logo.png":     {Data: png},
+		"secret/key.json":  {Data: []byte(`{}`)},
+		"big.json":         {Data: make([]byte, 2048)},
+		"tmp.bak":          {Data: []byte("backup")},
+	}
+	provider := NewFSResourceProvider(fsys, "")
+	provider.Exclude = []string{"secret", "*.bak"}
+	provider.MaxFileSize = 1024
+
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapResourcesProvider: provider,
+	}
+	clientProvider := &testClientImpl{roots_ListChanged: make(chan struct{})}
+	clientInstance := &ClientImpl{
+		CapRootsProvider:    clientProvider,
+		CapSamplingProvider: clientProvider,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		response, err := ts.Client.ResourcesList(ts.Ctx, "")
+		if err != nil {
+			t.Fatalf("ResourcesList failed: %v", err)
+		}
+		var uris []string
+		for _, resource := range response.Resources {
+			uris = append(uris, resource.URI)
+			if resource.URI == "file:///img/logo.png" && (resource.MimeType != "image/png" || resource.Size != int64(len(png))) {
+				t.Errorf("Unexpected resource: %+v", resource)
+			}
+		}
+		sort.Strings(uris)
+		want := []string{"file:///data/config.json", "file:///docs/guide.html", "file:///docs/my%20notes", "file:///img/logo.png", "file:///readme"}
+		if len(uris) != len(want) {
+			t.Fatalf("Expected %v, got %v", want, uris)
+		}
+		for i := range want {
+			if uris[i] != want[i] {
+				t.Errorf("Expected %v, got %v", want, uris)
+				break
+			}
+		}
+	})
+
+	t.Run("ReadText", func(t *testing.T) {
+		content, err := ts.Client.ResourcesRead(ts.Ctx, "file:///docs/my%20notes")
+		if err != nil || len(content) != 1 {
+			t.Fatalf("Unexpected content: %v, %v", content, err)
+		}
+		if content[0].Text != "notes" || content[0].MimeType != "text/plain; charset=utf-8" {
+			t.Errorf("Unexpected content: %+v", content[0])
+		}
+		content, err = ts.Client.ResourcesRead(ts.Ctx, "file:///data/config.json")
+		if err != nil || len(content) != 1 || content[0].Text != `{"a": 1}` {
+			t.Errorf("Unexpected content: %v, %v", content, err)
+		}
+	})
+
+	t.Run("ReadBlob", func(t *testing.T) {
+		content, err := ts.Client.ResourcesRead(ts.Ctx, "file:///img/logo.png")
+		if err != nil || len(content) != 1 {
+			t.Fatalf("Unexpected content: %v, %v", content, err)
+		}
+		if content[0].Text != "" || content[0].Blob != base64.StdEncoding.EncodeToString(png) {
+			t.Errorf("Unexpected content: %+v", content[0])
+		}
+	})
+
+	t.Run("Hidden", func(t *testing.T) {
+		for _, uri := range []string{
+			"file:///secret/key.json", "file:///tmp.bak", "file:///big.json",
+			"file:///missing", "file:///../readme", "file:///docs", "other:///readme",
+		} {
+			if _, err := ts.Client.ResourcesRead(ts.Ctx, uri); err == nil {
+				t.Errorf("Expected error reading %q", uri)
+			}
+		}
+	})
+
+	t.Run("Include", func(t *testing.T) {
+		provider := NewFSResourceProvider(fsys, "assets://")
+		provider.Include = []string{"docs/*", "*.png"}
+		var uris []string
+		for _, resource := range provider.Resources_OnList("") {
+			uris = append(uris, resource.URI)
+		}
+		sort.Strings(uris)
+		if len(uris) != 3 || uris[0] != "assets://docs/guide.html" || uris[2] != "assets://img/logo.png" {
+			t.Errorf("Unexpected resources: %v", uris)
+		}
+	})
+}
+
+func TestDirResourceProvider(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	for _, f := range []struct{ dir, name string }{{dir, "readme"}, {outside, "secret"}} {
+		if err := os.WriteFile(filepath.Join(f.dir, f.name), []byte(f.name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret"), filepath.Join(dir, "link")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "linkdir")); err != nil {
+		t.Fatal(err)
+	}
+	provider := NewDirResourceProvider(dir, "")
+
+	want := "file://" + filepath.ToSlash(dir) + "/readme"
+	if !strings.HasPrefix(want, "file:///") {
+		want = "file:///" + strings.TrimPrefix(want, "file://")
+	}
+	resources := provider.Resources_OnList("")
+	if len(resources) != 1 || resources[0].URI != want {
+		t.Fatalf("Unexpected resources: %+v", resources)
+	}
+	if content := provider.Resources_OnRead(want); len(content) != 1 || content[0].Text != "readme" {
+		t.Errorf("Unexpected content: %+v", content)
+	}
+	for _, name := range []string{"link", "linkdir/secret"} {
+		if content := provider.Resources_OnRead(provider.URIOf(name)); content != nil {
+			t.Errorf("Expected %s not to be read, got %+v", name, content)
+		}
+	}
+}
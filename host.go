@@ -0,0 +1,426 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	DefaultHostMinRetryDelay = 500 * time.Millisecond
+	DefaultHostMaxRetryDelay = 30 * time.Second
+)
+
+var (
+	ErrInvalidHostConfig = errors.New("mcp: invalid host configuration")
+	ErrUnknownServer     = errors.New("mcp: unknown server")
+	ErrServerNotReady    = errors.New("mcp: server not ready")
+	ErrConnectionLost    = errors.New("mcp: connection lost")
+)
+
+// ConnState is the state of the connection of a [Host] to a server.
+type ConnState int
+
+const (
+	ConnConnecting ConnState = iota
+	ConnReady
+	ConnFailed
+	ConnStopped
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case ConnConnecting:
+		return "connecting"
+	case ConnReady:
+		return "ready"
+	case ConnFailed:
+		return "failed"
+	case ConnStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// HostServer configures a server of a [Host].
+type HostServer struct {
+	// Name of the server, which namespaces its tools and prompts.
+	Name string
+	// Dial opens a connection to the server.
+	Dial func(ctx context.Context) (io.ReadWriteCloser, error)
+	// Provider returns the provider of a new client of the server, defaults
+	// to a ClientImpl without capabilities.
+	Provider func() ClientProvider
+}
+
+// ServerStatus is the status of a server of a [Host], for display.
+type ServerStatus struct {
+	Name  string
+	State ConnState
+	// last error, until the server is ready again
+	Err error
+	// time of the last change of state
+	Since time.Time
+	// number of connections made after the first
+	Reconnects int
+	// number of tools and prompts of a ready server
+	Tools   int
+	Prompts int
+}
+
+// Host connects to several servers at once and keeps a combined catalog of
+// their tools and prompts, named after their servers. Servers are reconnected
+// with an exponential delay when their connection fails.
+type Host struct {
+	// Separator joins the names of servers and of their tools and prompts,
+	// defaults to DefaultNamespaceSeparator.
+	Separator string
+	// MinRetryDelay and MaxRetryDelay bound the delay before reconnecting to
+	// a failed server, defaults to DefaultHostMinRetryDelay and
+	// DefaultHostMaxRetryDelay.
+	MinRetryDelay time.Duration
+	MaxRetryDelay time.Duration
+	// OnStatus is called with the status of a server whenever it changes, if
+	// not nil.
+	OnStatus func(ServerStatus)
+	// OnCatalogChanged is called when tools or prompts are added or removed,
+	// if not nil.
+	OnCatalogChanged func()
+	Logger           *slog.Logger
+
+	servers []*hostServer
+	mutex   sync.Mutex
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type hostServer struct {
+	config  HostServer
+	status  ServerStatus
+	client  *ClientState
+	tools   []ToolSpec
+	prompts []PromptSpec
+}
+
+// NewHost returns a host of servers, or an error wrapping
+// ErrInvalidHostConfig if their names are empty or not unique, or they cannot
+// be dialed.
+func NewHost(servers ...HostServer) (*Host, error) {
+	h := &Host{
+		Separator:     DefaultNamespaceSeparator,
+		MinRetryDelay: DefaultHostMinRetryDelay,
+		MaxRetryDelay: DefaultHostMaxRetryDelay,
+	}
+	names := make(map[string]bool)
+	now := time.Now()
+	for _, config := range servers {
+		switch {
+		case config.Name == "":
+			return nil, fmt.Errorf("%w: server without name", ErrInvalidHostConfig)
+		case names[config.Name]:
+			return nil, fmt.Errorf("%w: duplicate server %q", ErrInvalidHostConfig, config.Name)
+		case config.Dial == nil:
+			return nil, fmt.Errorf("%w: server %q cannot be dialed", ErrInvalidHostConfig, config.Name)
+		}
+		names[config.Name] = true
+		h.servers = append(h.servers, &hostServer{
+			config: config,
+			status: ServerStatus{Name: config.Name, State: ConnConnecting, Since: now},
+		})
+	}
+	return h, nil
+}
+
+// Start connects to the servers in the background, until ctx is done or the
+// host closed.
+func (h *Host) Start(ctx context.Context) {
+	ctx, h.cancel = context.WithCancel(ctx)
+	for _, s := range h.servers {
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			h.supervise(ctx, s)
+		}()
+	}
+}
+
+// Close disconnects from the servers.
+func (h *Host) Close() {
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.wg.Wait()
+}
+
+// supervise keeps s connected until ctx is done.
+func (h *Host) supervise(ctx context.Context, s *hostServer) {
+	delay := h.MinRetryDelay
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			h.update(s, func(status *ServerStatus) {
+				status.State = ConnConnecting
+				status.Reconnects++
+			})
+		}
+
+		client, err := h.connect(ctx, s)
+		if err == nil {
+			delay = h.MinRetryDelay
+			select {
+			case <-ctx.Done():
+				client.Close()
+			case <-client.Done():
+				err = ErrConnectionLost
+			}
+			h.disconnect(s)
+		}
+		if ctx.Err() != nil {
+			h.update(s, func(status *ServerStatus) { status.State = ConnStopped })
+			return
+		}
+
+		if h.Logger != nil {
+			h.Logger.Warn("server failed", "server", s.config.Name, "error", err, "retry", delay)
+		}
+		h.update(s, func(status *ServerStatus) { status.State, status.Err = ConnFailed, err })
+		select {
+		case <-ctx.Done():
+			h.update(s, func(status *ServerStatus) { status.State = ConnStopped })
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, h.MaxRetryDelay)
+	}
+}
+
+// connect connects to s, and lists its tools and prompts.
+func (h *Host) connect(ctx context.Context, s *hostServer) (*ClientState, error) {
+	conn, err := s.config.Dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var provider ClientProvider
+	if s.config.Provider != nil {
+		provider = s.config.Provider()
+	}
+	var logger *slog.Logger
+	if h.Logger != nil {
+		logger = h.Logger.With("server", s.config.Name)
+	}
+	client, err := Connect(ctx, conn, provider, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	tools, err := h.listTools(ctx, client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	prompts, err := h.listPrompts(ctx, client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	client.OnToolsListChanged(func() {
+		if tools, err := h.listTools(ctx, client); err == nil {
+			h.setCatalog(s, client, func() { s.tools = tools })
+		}
+	})
+	client.OnPromptsListChanged(func() {
+		if prompts, err := h.listPrompts(ctx, client); err == nil {
+			h.setCatalog(s, client, func() { s.prompts = prompts })
+		}
+	})
+
+	h.setCatalog(s, nil, func() {
+		s.client, s.tools, s.prompts = client, tools, prompts
+		s.status.State, s.status.Err, s.status.Since = ConnReady, nil, time.Now()
+	})
+	return client, nil
+}
+
+// disconnect drops the client and the catalog of s.
+func (h *Host) disconnect(s *hostServer) {
+	h.setCatalog(s, nil, func() {
+		s.client, s.tools, s.prompts = nil, nil, nil
+	})
+}
+
+func (h *Host) listTools(ctx context.Context, client *ClientState) ([]ToolSpec, error) {
+	if caps := client.ctx.GetSession().GetServerCapabilities(); caps == nil || caps.Tools == nil {
+		return nil, nil
+	}
+	var tools []ToolSpec
+	for tool, err := range client.AllTools(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+func (h *Host) listPrompts(ctx context.Context, client *ClientState) ([]PromptSpec, error) {
+	if caps := client.ctx.GetSession().GetServerCapabilities(); caps == nil || caps.Prompts == nil {
+		return nil, nil
+	}
+	var prompts []PromptSpec
+	for prompt, err := range client.AllPrompts(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, prompt)
+	}
+	return prompts, nil
+}
+
+// update changes the status of s, and reports it.
+func (h *Host) update(s *hostServer, change func(*ServerStatus)) {
+	h.mutex.Lock()
+	previous := s.status.State
+	change(&s.status)
+	if s.status.State != previous {
+		s.status.Since = time.Now()
+	}
+	status := s.status
+	h.mutex.Unlock()
+	if h.OnStatus != nil {
+		h.OnStatus(status)
+	}
+}
+
+// setCatalog changes the catalog of s, if client is nil or still the client
+// of s, and reports it.
+func (h *Host) setCatalog(s *hostServer, client *ClientState, change func()) {
+	h.mutex.Lock()
+	if client != nil && s.client != client {
+		h.mutex.Unlock()
+		return
+	}
+	change()
+	s.status.Tools, s.status.Prompts = len(s.tools), len(s.prompts)
+	status := s.status
+	h.mutex.Unlock()
+	if h.OnStatus != nil {
+		h.OnStatus(status)
+	}
+	if h.OnCatalogChanged != nil {
+		h.OnCatalogChanged()
+	}
+}
+
+// Status returns the status of the servers, in order.
+func (h *Host) Status() []ServerStatus {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	statuses := make([]ServerStatus, 0, len(h.servers))
+	for _, s := range h.servers {
+		statuses = append(statuses, s.status)
+	}
+	return statuses
+}
+
+// Client returns the client of the server named name, or false if it is not
+// ready.
+func (h *Host) Client(name string) (*ClientState, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for _, s := range h.servers {
+		if s.config.Name == name && s.client != nil {
+			return s.client, true
+		}
+	}
+	return nil, false
+}
+
+// Tools returns the tools of the ready servers, named Name+Separator+tool.
+func (h *Host) Tools() []ToolSpec {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	var tools []ToolSpec
+	for _, s := range h.servers {
+		for _, tool := range s.tools {
+			tool.Name = s.config.Name + h.Separator + tool.Name
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// AllTools returns an iterator over Tools, so that a host can stand for a
+// client of all its servers.
+func (h *Host) AllTools(ctx context.Context, opts ...PageOption) iter.Seq2[ToolSpec, error] {
+	return func(yield func(ToolSpec, error) bool) {
+		for _, tool := range h.Tools() {
+			if !yield(tool, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Prompts returns the prompts of the ready servers, named
+// Name+Separator+prompt.
+func (h *Host) Prompts() []PromptSpec {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	var prompts []PromptSpec
+	for _, s := range h.servers {
+		for _, prompt := range s.prompts {
+			prompt.Name = s.config.Name + h.Separator + prompt.Name
+			prompts = append(prompts, prompt)
+		}
+	}
+	return prompts
+}
+
+// route returns the client of the server of the qualified name, and the name
+// in the server. The longest matching server name wins, so that "a__b__x" goes
+// to server "a__b" rather than "a".
+func (h *Host) route(qualified string) (*ClientState, string, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	var server *hostServer
+	var name string
+	for _, s := range h.servers {
+		if server != nil && len(s.config.Name) <= len(server.config.Name) {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(qualified, s.config.Name+h.Separator); ok {
+			server, name = s, rest
+		}
+	}
+	if server == nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnknownServer, qualified)
+	}
+	if server.client == nil {
+		return nil, "", fmt.Errorf("%w: %s is %s", ErrServerNotReady, server.config.Name, server.status.State)
+	}
+	return server.client, name, nil
+}
+
+// ToolCall calls the tool of the qualified name, as listed by Tools.
+func (h *Host) ToolCall(ctx context.Context, name string, args map[string]string) (ToolCallResponse, error) {
+	client, name, err := h.route(name)
+	if err != nil {
+		return ToolCallResponse{}, err
+	}
+	return client.ToolCall(ctx, name, args)
+}
+
+// PromptsGet gets the prompt of the qualified name, as listed by Prompts.
+func (h *Host) PromptsGet(ctx context.Context, name string, args map[string]string) (PromptGetResponse, error) {
+	client, name, err := h.route(name)
+	if err != nil {
+		return PromptGetResponse{}, err
+	}
+	return client.PromptsGet(ctx, name, args)
+}
@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testDialer serves a new test server on each dial, keeping the last one so
+// that tests can break the connection or change its tools.
+type testDialer struct {
+	conns     chan net.Conn
+	providers chan *testServerImpl
+}
+
+func newTestDialer() *testDialer {
+	return &testDialer{conns: make(chan net.Conn, 10), providers: make(chan *testServerImpl, 10)}
+}
+
+func (d *testDialer) Dial(ctx context.Context) (io.ReadWriteCloser, error) {
+	sconn, cconn := net.Pipe()
+	provider := NewTestServerImpl()
+	serverImpl := &ServerImpl{
+		MCPVersionNegotiator: provider,
+		CapToolsProvider:     provider,
+		CapPromptsProvider:   provider,
+	}
+	server := NewServer(sconn)
+	server.Setup(serverImpl)
+	server.SetMCPVersion(LatestMCPVersion)
+	server.SetCapabilities(serverImpl.Capabilities())
+	go server.Serve()
+	d.conns <- sconn
+	d.providers <- provider
+	return cconn, nil
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHost(t *testing.T) {
+	if _, err := NewHost(HostServer{Name: "a", Dial: newTestDialer().Dial}, HostServer{Name: "a", Dial: newTestDialer().Dial}); !errors.Is(err, ErrInvalidHostConfig) {
+		t.Errorf("Expected duplicate names to be rejected, got %v", err)
+	}
+	if _, err := NewHost(HostServer{Name: "a"}); !errors.Is(err, ErrInvalidHostConfig) {
+		t.Errorf("Expected server without Dial to be rejected, got %v", err)
+	}
+
+	t.Run("NestedNames", func(t *testing.T) {
+		outer, inner := newTestDialer(), newTestDialer()
+		nested, err := NewHost(HostServer{Name: "a", Dial: outer.Dial}, HostServer{Name: "a__b", Dial: inner.Dial})
+		if err != nil {
+			t.Fatalf("NewHost failed: %v", err)
+		}
+		nested.Start(context.Background())
+		defer nested.Close()
+		for _, name := range []string{"a", "a__b"} {
+			waitFor(t, name+" to be ready", func() bool {
+				for _, s := range nested.Status() {
+					if s.Name == name {
+						return s.State == ConnReady
+					}
+				}
+				return false
+			})
+		}
+		for qualified, want := range map[string]struct{ server, name string }{
+			"a__test_tool":    {"a", "test_tool"},
+			"a__b__test_tool": {"a__b", "test_tool"},
+			"a__bc__tool":     {"a", "bc__tool"},
+		} {
+			client, name, err := nested.route(qualified)
+			if expected, _ := nested.Client(want.server); err != nil || client != expected || name != want.name {
+				t.Errorf("route(%q) = %v, %q, %v, want server %s and %q", qualified, client, name, err, want.server, want.name)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		response, err := nested.ToolCall(ctx, "a__b__test_tool", nil)
+		if err != nil || response.Content[0].Text != "Tool executed successfully" {
+			t.Errorf("Unexpected response: %+v, %v", response, err)
+		}
+	})
+
+	dialer := newTestDialer()
+	refused := errors.New("connection refused")
+	host, err := NewHost(
+		HostServer{Name: "test", Dial: dialer.Dial},
+		HostServer{Name: "broken", Dial: func(ctx context.Context) (io.ReadWriteCloser, error) { return nil, refused }},
+	)
+	if err != nil {
+		t.Fatalf("NewHost failed: %v", err)
+	}
+	host.MinRetryDelay, host.MaxRetryDelay = 10*time.Millisecond, 20*time.Millisecond
+	catalogChanged := make(chan struct{}, 10)
+	host.OnCatalogChanged = func() { catalogChanged <- struct{}{} }
+	host.Start(context.Background())
+	defer host.Close()
+
+	status := func(name string) ServerStatus {
+		for _, s := range host.Status() {
+			if s.Name == name {
+				return s
+			}
+		}
+		t.Fatalf("No status for %s", name)
+		return ServerStatus{}
+	}
+
+	t.Run("Status", func(t *testing.T) {
+		waitFor(t, "test to be ready", func() bool { return status("test").State == ConnReady })
+		if s := status("test"); s.Tools != 1 || s.Prompts != 1 || s.Err != nil {
+			t.Errorf("Unexpected status: %+v", s)
+		}
+		waitFor(t, "broken to fail", func() bool { return status("broken").State == ConnFailed })
+		if s := status("broken"); !errors.Is(s.Err, refused) {
+			t.Errorf("Expected last error to be kept, got %+v", s)
+		}
+	})
+
+	t.Run("Catalog", func(t *testing.T) {
+		var tools, prompts []string
+		for _, tool := range host.Tools() {
+			tools = append(tools, tool.Name)
+		}
+		for _, prompt := range host.Prompts() {
+			prompts = append(prompts, prompt.Name)
+		}
+		if !reflect.DeepEqual(tools, []string{"test__test_tool"}) || !reflect.DeepEqual(prompts, []string{"test__test_prompt"}) {
+			t.Errorf("Unexpected catalog: %v, %v", tools, prompts)
+		}
+	})
+
+	t.Run("ToolCall", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		response, err := host.ToolCall(ctx, "test__test_tool", nil)
+		if err != nil || response.Content[0].Text != "Tool executed successfully" {
+			t.Errorf("Unexpected response: %+v, %v", response, err)
+		}
+		if _, err := host.ToolCall(ctx, "broken__tool", nil); !errors.Is(err, ErrServerNotReady) {
+			t.Errorf("Expected ErrServerNotReady, got %v", err)
+		}
+		if _, err := host.ToolCall(ctx, "unknown__tool", nil); !errors.Is(err, ErrUnknownServer) {
+			t.Errorf("Expected ErrUnknownServer, got %v", err)
+		}
+	})
+
+	t.Run("ListChanged", func(t *testing.T) {
+		for len(catalogChanged) > 0 {
+			<-catalogChanged
+		}
+		provider := <-dialer.providers
+		provider.tools_ListChanged <- struct{}{}
+		select {
+		case <-catalogChanged:
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for the catalog to change")
+		}
+	})
+
+	t.Run("Reconnect", func(t *testing.T) {
+		(<-dialer.conns).Close()
+		waitFor(t, "test to reconnect", func() bool {
+			s := status("test")
+			return s.State == ConnReady && s.Reconnects == 1
+		})
+		if _, ok := host.Client("test"); !ok {
+			t.Error("Expected a client of test")
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		host.Close()
+		for _, s := range host.Status() {
+			if s.State != ConnStopped {
+				t.Errorf("Expected %s to be stopped, got %s", s.Name, s.State)
+			}
+		}
+		if len(host.Tools()) != 0 {
+			t.Errorf("Expected empty catalog, got %v", host.Tools())
+		}
+	})
+}
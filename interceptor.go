@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+// Invocation describes a single MCP method dispatch passing through an
+// interceptor chain.
+type Invocation struct {
+	// Method is the MCP method name, e.g. "tools/call".
+	Method string
+	// Params points to the decoded parameters of the call, e.g.
+	// *ToolCallRequest. It is nil for methods without parameters. Interceptors
+	// may modify the pointed-to value before calling the next handler.
+	Params any
+	// Session is the session the call belongs to.
+	Session Session
+}
+
+// MethodHandler dispatches an [Invocation] and returns its result.
+//
+// An error returned as *[jsonrpc2.ErrorObject] is sent to the remote peer as
+// is; any other error is reported as an internal error.
+type MethodHandler func(ctx context.Context, inv *Invocation) (any, error)
+
+// Interceptor wraps the dispatch of every MCP method. It may inspect or modify
+// the invocation, call next to continue the chain and inspect the result, or
+// short-circuit by returning without calling next, usually with a
+// *[jsonrpc2.ErrorObject].
+//
+// Interceptors are installed with [ServerImpl.Use] for incoming requests, and
+// [ClientState.Use] for outgoing calls and the requests of the server handled
+// by a [ClientImpl]. Incoming requests that cannot be decoded or are not
+// supported pass through the chain too, with nil Params, the final handler
+// returning their error.
+type Interceptor func(ctx context.Context, inv *Invocation, next MethodHandler) (any, error)
+
+// chainInterceptors builds a MethodHandler that runs interceptors in order,
+// the first one being the outermost.
+func chainInterceptors(interceptors []Interceptor, final MethodHandler) MethodHandler {
+	h := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], h
+		h = func(ctx context.Context, inv *Invocation) (any, error) {
+			return interceptor(ctx, inv, next)
+		}
+	}
+	return h
+}
+
+// toErrorObject converts an error returned by a MethodHandler into the error
+// object sent on the wire. Errors other than error objects are reported as
+// internal errors without their text, which is logged to logger if not nil.
+func toErrorObject(err error, logger *slog.Logger) jsonrpc2.ErrorObject {
+	var erro *jsonrpc2.ErrorObject
+	if errors.As(err, &erro) {
+		return *erro
+	}
+	var errv jsonrpc2.ErrorObject
+	if errors.As(err, &errv) {
+		return errv
+	}
+	if logger != nil {
+		logger.Error("internal error", "error", err)
+	}
+	return jsonrpc2.ErrObjInternalError
+}
+
+// resultAs converts the result of an interceptor chain into R. Results
+// replaced by an interceptor with a value of a different type are converted
+// through their JSON encoding.
+func resultAs[R any](res any) (R, error) {
+	var result R
+	switch v := res.(type) {
+	case nil:
+		return result, nil
+	case R:
+		return v, nil
+	case *R:
+		if v != nil {
+			return *v, nil
+		}
+		return result, nil
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
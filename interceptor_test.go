@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+func TestInterceptors(t *testing.T) {
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapPromptsProvider:   serverProvider,
+		CapToolsProvider:     serverProvider,
+	}
+	var mu sync.Mutex
+	var serverMethods []string
+	serverInstance.Use(
+		func(ctx context.Context, inv *Invocation, next MethodHandler) (any, error) {
+			mu.Lock()
+			serverMethods = append(serverMethods, inv.Method)
+			mu.Unlock()
+			if inv.Session == nil {
+				t.Error("Expected session in invocation")
+			}
+			return next(ctx, inv)
+		},
+		func(ctx context.Context, inv *Invocation, next MethodHandler) (any, error) {
+			if msg, ok := inv.Params.(*ToolCallRequest); ok && msg.Arguments["param1"] == "forbidden" {
+				return nil, &jsonrpc2.ErrorObject{Code: jsonrpc2.JSONRPC2ErrorInvalidRequest, Message: "Forbidden"}
+			}
+			if msg, ok := inv.Params.(*ToolCallRequest); ok && msg.Name == "leak" {
+				return nil, errors.New("password of db1 rejected")
+			}
+			return next(ctx, inv)
+		},
+	)
+
+	clientProvider := &testClientImpl{roots_ListChanged: make(chan struct{})}
+	clientInstance := &ClientImpl{
+		CapRootsProvider:    clientProvider,
+		CapSamplingProvider: clientProvider,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	var clientResults []any
+	var clientIncoming []string
+	ts.Client.Use(func(ctx context.Context, inv *Invocation, next MethodHandler) (any, error) {
+		if msg, ok := inv.Params.(*SamplingMessage); ok {
+			mu.Lock()
+			clientIncoming = append(clientIncoming, inv.Method)
+			mu.Unlock()
+			if msg.SystemPrompt == "forbidden" {
+				return nil, &jsonrpc2.ErrorObject{Code: jsonrpc2.JSONRPC2ErrorInvalidRequest, Message: "Forbidden"}
+			}
+		}
+		return next(ctx, inv)
+	})
+	ts.Client.Use(func(ctx context.Context, inv *Invocation, next MethodHandler) (any, error) {
+		if inv.Method == kMethodPromptsList {
+			// short-circuit with a cached value of a different type
+			return []map[string]any{{"prompts": []map[string]any{{"name": "cached_prompt"}}}}, nil
+		}
+		res, err := next(ctx, inv)
+		clientResults = append(clientResults, res)
+		return res, err
+	})
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("ServerShortCircuit", func(t *testing.T) {
+		_, err := ts.Client.ToolCall(ts.Ctx, "test_tool", map[string]string{"param1": "forbidden"})
+		rpcErr, ok := err.(*jsonrpc2.ErrorObject)
+		if !ok {
+			t.Fatalf("Expected jsonrpc2.ErrorObject, got %T: %v", err, err)
+		}
+		if rpcErr.Message != "Forbidden" {
+			t.Errorf("Unexpected error: %v", rpcErr)
+		}
+
+		response, err := ts.Client.ToolCall(ts.Ctx, "test_tool", map[string]string{"param1": "value1"})
+		if err != nil {
+			t.Fatalf("ToolCall failed: %v", err)
+		}
+		if len(response.Content) == 0 {
+			t.Error("Expected non-empty response content")
+		}
+	})
+
+	t.Run("ServerObserve", func(t *testing.T) {
+		mu.Lock()
+		defer mu.Unlock()
+		expected := []string{kMethodInitialize, kMethodInitialized, kMethodToolsCall, kMethodToolsCall}
+		if len(serverMethods) != len(expected) {
+			t.Fatalf("Expected methods %v, got %v", expected, serverMethods)
+		}
+		for i := range expected {
+			if serverMethods[i] != expected[i] {
+				t.Errorf("Expected methods %v, got %v", expected, serverMethods)
+			}
+		}
+	})
+
+	t.Run("ClientShortCircuit", func(t *testing.T) {
+		prompts, err := ts.Client.PromptsList(ts.Ctx, "")
+		if err != nil {
+			t.Fatalf("PromptsList failed: %v", err)
+		}
+		if len(prompts) != 1 || len(prompts[0].Prompts) != 1 || prompts[0].Prompts[0].Name != "cached_prompt" {
+			t.Errorf("Unexpected prompts: %v", prompts)
+		}
+	})
+
+	t.Run("ClientObserve", func(t *testing.T) {
+		// initialize, initialized and two tools/call
+		if len(clientResults) != 4 {
+			t.Fatalf("Expected 4 observed results, got %d", len(clientResults))
+		}
+		if _, ok := clientResults[0].(ServerInitializeInfo); !ok {
+			t.Errorf("Expected ServerInitializeInfo, got %T", clientResults[0])
+		}
+	})
+
+	t.Run("InternalError", func(t *testing.T) {
+		_, err := ts.Client.ToolCall(ts.Ctx, "leak", nil)
+		rpcErr, ok := err.(*jsonrpc2.ErrorObject)
+		if !ok || rpcErr.Code != jsonrpc2.ErrObjInternalError.Code || rpcErr.Message != jsonrpc2.ErrObjInternalError.Message {
+			t.Errorf("Expected an opaque internal error, got %v", err)
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := callOf[struct{}](ts.Ctx, ts.Client, "unknown/method", nil)
+		if rpcErr, ok := err.(*jsonrpc2.ErrorObject); !ok || rpcErr.Code != jsonrpc2.ErrObjMethodNotSupported.Code {
+			t.Errorf("Expected method not supported, got %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if last := serverMethods[len(serverMethods)-1]; last != "unknown/method" {
+			t.Errorf("Expected the chain to see unknown/method, got %s", last)
+		}
+	})
+
+	t.Run("ClientIncoming", func(t *testing.T) {
+		message := func(systemPrompt string) SamplingMessage {
+			return SamplingMessage{
+				Messages:     []SamplingMessageItem{{Role: RoleUser, Content: TextContent("hi")}},
+				SystemPrompt: systemPrompt,
+				MaxTokens:    10,
+			}
+		}
+		_, err := ts.Server.CreateMessage(ts.Ctx, message("forbidden"))
+		if rpcErr, ok := err.(*jsonrpc2.ErrorObject); !ok || rpcErr.Message != "Forbidden" {
+			t.Errorf("Expected the client chain to reject the request, got %v", err)
+		}
+		if _, err := ts.Server.CreateMessage(ts.Ctx, message("")); err != nil {
+			t.Errorf("CreateMessage failed: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(clientIncoming) != 2 || clientIncoming[0] != kMethodSamplingCreateMessage {
+			t.Errorf("Expected the client chain to see both requests, got %v", clientIncoming)
+		}
+	})
+}
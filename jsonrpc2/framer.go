@@ -28,20 +28,15 @@ func NewLineFramer(w io.ReadWriteCloser) *LineFramer {
 }
 
 func (c *LineFramer) ReadFrame() ([]byte, error) {
-	for {
-		if c.scanner.Scan() {
-			return c.scanner.Bytes()[:], nil
-		}
-		err := c.scanner.Err()
-		if err == bufio.ErrFinalToken {
-			err = io.EOF
-		} else if err == io.ErrClosedPipe {
-			err = io.EOF
-		}
-		if err != nil {
-			return c.scanner.Bytes()[:], err
-		}
+	if c.scanner.Scan() {
+		return c.scanner.Bytes()[:], nil
+	}
+	// Scan returns false with a nil error at EOF
+	err := c.scanner.Err()
+	if err == nil || err == bufio.ErrFinalToken || err == io.ErrClosedPipe {
+		err = io.EOF
 	}
+	return c.scanner.Bytes()[:], err
 }
 
 func (c *LineFramer) WriteFrame(input []byte) error {
@@ -0,0 +1,55 @@
+package jsonrpc2
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator allocates the IDs of the requests sent by a [Peer].
+// Implementations must be safe for concurrent use and must not return the same
+// ID twice within a session.
+type IDGenerator interface {
+	NextID() ID
+}
+
+// IDGeneratorFunc adapts a function to the [IDGenerator] interface.
+type IDGeneratorFunc func() ID
+
+func (f IDGeneratorFunc) NextID() ID {
+	return f()
+}
+
+// NumberIDGenerator generates sequential numeric IDs starting from 1. The zero
+// value is ready to use.
+type NumberIDGenerator struct {
+	last atomic.Int64
+}
+
+func (g *NumberIDGenerator) NextID() ID {
+	return MakeNumberID(g.last.Add(1))
+}
+
+// UUIDGenerator generates random UUID string IDs.
+type UUIDGenerator struct{}
+
+func (UUIDGenerator) NextID() ID {
+	return MakeStringID(uuid.NewString())
+}
+
+// PrefixedIDGenerator generates sequential string IDs made of a fixed prefix
+// and a number, e.g. "upstream-1". It is useful for proxies that forward
+// requests from several peers and need their IDs not to clash.
+type PrefixedIDGenerator struct {
+	prefix string
+	last   atomic.Int64
+}
+
+func NewPrefixedIDGenerator(prefix string) *PrefixedIDGenerator {
+	return &PrefixedIDGenerator{prefix: prefix}
+}
+
+func (g *PrefixedIDGenerator) NextID() ID {
+	return MakeStringID(fmt.Sprintf("%s%d", g.prefix, g.last.Add(1)))
+}
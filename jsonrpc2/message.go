@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/google/uuid"
 )
 
 // ID is a request/response identifier. It can be either a string or an integer.
@@ -12,13 +16,19 @@ import (
 //   - implements [pkg/encoding/json.Marshaler].
 type ID struct {
 	name   string
-	number int32
+	number int64
 }
 
-func makeNumberID(n int32) ID {
+// MakeNumberID returns a numeric ID.
+func MakeNumberID(n int64) ID {
 	return ID{number: n}
 }
 
+// MakeStringID returns a string ID. The name must not be empty.
+func MakeStringID(name string) ID {
+	return ID{name: name}
+}
+
 func (id ID) MarshalJSON() ([]byte, error) {
 	if id.name != "" {
 		return json.Marshal(id.name)
@@ -46,9 +56,9 @@ type wireUnion struct {
 	Method  string           `json:"method,omitempty"` // for request
 	Params  *json.RawMessage `json:"params,omitempty"` // for request
 
-	Result *json.RawMessage `json:"result,omitempty"` // for normal response
-	Error  *ErrorObject     `json:"error,omitempty"`  // for error response
-	ID     *ID              `json:"id,omitempty"`     // for request and response
+	Result json.RawMessage `json:"result,omitempty"` // for normal response, "null" if the result is null
+	Error  *ErrorObject    `json:"error,omitempty"`  // for error response
+	ID     *ID             `json:"id,omitempty"`     // for request and response
 }
 
 func (d wireUnion) IsResponse() bool {
@@ -59,6 +69,20 @@ func (d wireUnion) IsError() bool {
 	return d.Error != nil
 }
 
+// validate checks that d is a well-formed request, notification or response.
+func (d wireUnion) validate() error {
+	if d.Version != JSONRPC2Version {
+		return fmt.Errorf("%w: unsupported version %q", ErrInvalidMessage, d.Version)
+	}
+	if d.Result != nil && d.Error != nil {
+		return fmt.Errorf("%w: both result and error are set", ErrInvalidMessage)
+	}
+	if d.Result == nil && d.Error == nil && d.Method == "" {
+		return fmt.Errorf("%w: missing method", ErrInvalidMessage)
+	}
+	return nil
+}
+
 type requestData struct {
 	Version string           `json:"jsonrpc"`
 	Method  string           `json:"method,omitempty"`
@@ -122,9 +146,63 @@ func (e RPCError) Error() string {
 	return fmt.Sprintf("RPC error: %v", e.child)
 }
 
+// PanicError is returned in place of the error of a [Handler] that panicked
+// while handling a request. The remote peer receives an internal error whose
+// data carries the incident ID, so that it can be matched against the logged
+// stack trace.
+type PanicError struct {
+	Incident string
+	Value    any
+}
+
+func (e PanicError) Error() string {
+	return fmt.Sprintf("jsonrpc2: panic in handler (incident %s): %v", e.Incident, e.Value)
+}
+
+// NewPanicError returns the PanicError of value, recovered from a panic while
+// handling method, under a new incident ID. The incident is logged to logger
+// with the stack trace, if logger is not nil. It must be called from the
+// deferred function recovering the panic, for the trace to show its origin.
+func NewPanicError(logger *slog.Logger, method string, value any) PanicError {
+	perr := PanicError{Incident: uuid.NewString(), Value: value}
+	if logger != nil {
+		logger.Error("panic handling request", "method", method, "incident", perr.Incident,
+			"panic", value, "stack", string(debug.Stack()))
+	}
+	return perr
+}
+
+// ErrorObjectOf converts an error returned by a [Handler] into the error object
+// sent to the remote peer: a [PanicError] is an internal error carrying its
+// incident ID, and errors other than error objects are internal errors.
+func ErrorObjectOf(err error) ErrorObject {
+	var perr PanicError
+	if errors.As(err, &perr) {
+		obj := ErrObjInternalError
+		data, _ := json.Marshal(struct {
+			Incident string `json:"incident"`
+		}{perr.Incident})
+		obj.Data = (*json.RawMessage)(&data)
+		return obj
+	}
+	var erro *ErrorObject
+	if errors.As(err, &erro) {
+		return *erro
+	}
+	var errv ErrorObject
+	if errors.As(err, &errv) {
+		return errv
+	}
+	return ErrObjInternalError
+}
+
 var (
 	ErrInvalidContent = errors.New("jsonrpc2: invalid content")
+	ErrInvalidMessage = errors.New("jsonrpc2: invalid message")
 	ErrContextCancel  = errors.New("jsonrpc2: context canceled")
 	// When a request is received and the handler cannot be found, this error will be returned.
 	ErrNoHandler = errors.New("jsonrpc2: no handler provided")
+	// When more consecutive malformed frames than allowed are received, the peer
+	// is shut down with this error.
+	ErrTooManyMalformedFrames = errors.New("jsonrpc2: too many malformed frames")
 )
@@ -14,12 +14,20 @@ type Peer struct {
 	pendingRequests map[ID]PendingRequest
 	handler         Handler
 
-	// as a client, how many requests we have sent
-	requestCount int32
+	// as a client, allocates the IDs of the requests we send
+	idGenerator IDGenerator
+
+	// number of malformed frames received in a row, and the allowed maximum
+	malformedFrames    int
+	maxMalformedFrames int
 
 	mutex sync.Mutex
 }
 
+// DefaultMaxMalformedFrames is the number of consecutive malformed frames that
+// shut a [Peer] down.
+var DefaultMaxMalformedFrames = 16
+
 // NewPeer creates a new Peer instance with the given context, framer, and
 // handler.
 //   - If the peer is used as a server, the handler must be provided to handle
@@ -36,8 +44,10 @@ func NewPeer(pctx context.Context, framer Framer, handler Handler) *Peer {
 			frameWriteChan: make(chan []byte, 1),
 			cancelFunc:     cancelFunc,
 		},
-		pendingRequests: make(map[ID]PendingRequest),
-		handler:         handler,
+		pendingRequests:    make(map[ID]PendingRequest),
+		handler:            handler,
+		idGenerator:        new(NumberIDGenerator),
+		maxMalformedFrames: DefaultMaxMalformedFrames,
 	}
 	context.AfterFunc(ctx, func() {
 		if peer.logger != nil {
@@ -52,6 +62,26 @@ func (p *Peer) SetLogger(logger *slog.Logger) {
 	p.logger = logger
 }
 
+// SetIDGenerator sets the generator of the IDs of the requests sent by the
+// peer. It defaults to a [NumberIDGenerator] and must be set before
+// [Peer.Start].
+func (p *Peer) SetIDGenerator(g IDGenerator) {
+	p.idGenerator = g
+}
+
+// SetMaxMalformedFrames sets how many malformed frames in a row shut the peer
+// down; the ones before are answered with an error. A value of 0 or less means
+// no limit. It must be called before [Peer.Start].
+func (p *Peer) SetMaxMalformedFrames(n int) {
+	p.maxMalformedFrames = n
+}
+
+// Done returns a channel closed when the peer shuts down, once its connection
+// fails or its context is done.
+func (p *Peer) Done() <-chan struct{} {
+	return p.ctx.Done()
+}
+
 // Start starts the peer and begins serving incoming requests. It must be called
 // once after the peer is setup. It is automatically called with [Peer.Call] and
 // [Peer.Notify].
@@ -113,8 +143,16 @@ func (p *Peer) Notify(method string, params any) error {
 //
 // NOTE: The [PendingRequest] object MUST be passed to either
 // [PendingRequest.Cancel] or [PendingRequest.RecvResponse], otherwise the call
-// will hang indefinitely.
+// will hang until the peer is shut down. Use [Peer.CallContext] or [CallOf] to
+// bound the call with a context.
 func (p *Peer) Call(method string, params any) (*PendingRequest, error) {
+	return p.CallContext(p.ctx, method, params)
+}
+
+// CallContext is like [Peer.Call], but the returned [PendingRequest] is
+// canceled when ctx is done, so that [PendingRequest.RecvResponse] returns
+// instead of waiting for a response that may never come.
+func (p *Peer) CallContext(ctx context.Context, method string, params any) (*PendingRequest, error) {
 	p.Start()
 
 	var encoded_param json.RawMessage
@@ -124,8 +162,7 @@ func (p *Peer) Call(method string, params any) (*PendingRequest, error) {
 		return nil, RPCError{err}
 	}
 
-	p.requestCount++
-	id := makeNumberID(p.requestCount)
+	id := p.idGenerator.NextID()
 
 	req := requestData{
 		Version: JSONRPC2Version,
@@ -135,79 +172,160 @@ func (p *Peer) Call(method string, params any) (*PendingRequest, error) {
 	}
 
 	p.mutex.Lock()
-	ctx, cancelFunc := context.WithCancel(p.ctx)
+	reqCtx, cancelFunc := context.WithCancel(p.ctx)
+	stop := context.AfterFunc(ctx, cancelFunc)
+	// the channel is never closed: a response is delivered at most once, by
+	// the caller taking the request out of pendingRequests
 	channel := make(chan responseData, 1)
-	request := PendingRequest{id: id, ctx: ctx, cancelFunc: cancelFunc, channel: channel}
+	removed := make(chan struct{})
+	request := PendingRequest{id: id, ctx: reqCtx, cancelFunc: cancelFunc, channel: channel, removed: removed}
 	p.pendingRequests[id] = request
-	context.AfterFunc(ctx, func() {
+	context.AfterFunc(reqCtx, func() {
+		stop()
 		p.mutex.Lock()
 		delete(p.pendingRequests, id)
 		p.mutex.Unlock()
-		close(channel)
+		close(removed)
 	})
 	p.mutex.Unlock()
 
-	err = p.sendRequestOrNotification(ctx, req)
+	err = p.sendRequestOrNotification(reqCtx, req)
 	if err != nil {
+		request.Cancel()
 		return nil, RPCError{err}
 	}
 	return &request, nil
 }
 
+// CallOf calls method on the remote peer with the given parameters and waits
+// for its result. When ctx is done before the response is received, the
+// request is abandoned and the error of ctx is returned.
+func CallOf[P any, R any](ctx context.Context, p *Peer, method string, params P) (R, error) {
+	var result R
+	req, err := p.CallContext(ctx, method, params)
+	if err == nil {
+		err = req.RecvResponse(&result)
+	}
+	if err != nil && ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+	return result, err
+}
+
+// deliver gives response to the request of the given id, unless it was
+// answered or canceled already. The channel of a pending request is buffered
+// for its single response, so the send never blocks.
+func (p *Peer) deliver(id ID, response responseData) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	request, ok := p.pendingRequests[id]
+	if !ok {
+		return
+	}
+	delete(p.pendingRequests, id)
+	request.channel <- response
+}
+
 func (p *Peer) handleFrame(frame []byte) error {
 	var wireData wireUnion
 	err := json.Unmarshal(frame, &wireData)
+	if err == nil {
+		err = wireData.validate()
+	}
 	if err != nil {
-		erro := ErrObjParseError
-		erro.Data = &json.RawMessage{}
-		erro.Data.UnmarshalJSON(frame)
-		return erro
+		return p.handleMalformedFrame(frame, wireData, err)
 	}
+	p.malformedFrames = 0
+
 	if wireData.IsResponse() || wireData.IsError() {
 		if wireData.ID == nil {
-			p.logger.Error("received a error without an ID", "frame", string(frame))
+			if p.logger != nil {
+				p.logger.Error("received a error without an ID", "frame", string(frame))
+			}
 			response := responseData{
 				Error: wireData.Error,
 			}
 			p.mutex.Lock()
-			var requestChannels []chan<- responseData
-			for _, req := range p.pendingRequests {
-				requestChannels = append(requestChannels, req.channel)
+			for id, req := range p.pendingRequests {
+				delete(p.pendingRequests, id)
+				req.channel <- response
 			}
 			p.mutex.Unlock()
-
-			for _, ch := range requestChannels {
-				ch <- response
-			}
 		} else {
-			id := *wireData.ID
-			p.mutex.Lock()
-			request, ok := p.pendingRequests[id]
-			p.mutex.Unlock()
-			if ok {
-				response := responseData{
-					Result: wireData.Result,
-					Error:  wireData.Error,
-					ID:     wireData.ID,
-				}
-				select {
-				case <-request.ctx.Done():
-				case request.channel <- response:
-				}
+			response := responseData{
+				Error: wireData.Error,
+				ID:    wireData.ID,
 			}
+			if wireData.Result != nil {
+				response.Result = &wireData.Result
+			}
+			p.deliver(*wireData.ID, response)
 		}
 		return nil
 	}
-	// receive a request from the server
-	if p.handler != nil {
-		writer := ResponseWriter{
-			output: p.frameWriteChan,
-			id:     wireData.ID,
+	// receive a request from the remote peer
+	req := Request{Method: wireData.Method, Params: wireData.Params, id: wireData.ID}
+	writer := ResponseWriter{
+		ctx:    p.ctx,
+		output: p.frameWriteChan,
+		id:     wireData.ID,
+	}
+	if p.handler == nil {
+		if p.logger != nil {
+			p.logger.Error("error handling request", "method", req.Method, "error", ErrNoHandler)
+		}
+		if !req.IsNotification() {
+			writer.WriteError(ErrObjMethodNotSupported)
+		}
+		return nil
+	}
+
+	err = p.callHandler(&writer, req)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Error("error handling request", "method", req.Method, "error", err)
+		}
+		if !req.IsNotification() && !writer.written {
+			writer.WriteError(ErrorObjectOf(err))
 		}
-		return p.handler.HandleRequest(&writer, Request{Method: wireData.Method, Params: wireData.Params, id: wireData.ID})
-	} else {
-		return ErrNoHandler
 	}
+	return nil
+}
+
+// handleMalformedFrame answers a frame that is not valid JSON with a parse
+// error, and a frame that is not a valid JSON-RPC message with an invalid
+// request error. It returns [ErrTooManyMalformedFrames] instead when the limit
+// of malformed frames in a row is reached.
+func (p *Peer) handleMalformedFrame(frame []byte, wireData wireUnion, err error) error {
+	if p.logger != nil {
+		p.logger.Warn("received a malformed frame", "frame", string(frame), "error", err)
+	}
+
+	p.malformedFrames++
+	if p.maxMalformedFrames > 0 && p.malformedFrames >= p.maxMalformedFrames {
+		return ErrTooManyMalformedFrames
+	}
+
+	// the id is null unless it could be decoded
+	writer := ResponseWriter{
+		ctx:    p.ctx,
+		output: p.frameWriteChan,
+		id:     wireData.ID,
+	}
+	if !json.Valid(frame) {
+		return writer.WriteError(ErrObjParseError)
+	}
+	return writer.WriteError(ErrObjInvalidRequest)
+}
+
+// callHandler calls the handler of p, converting a panic into a [PanicError].
+func (p *Peer) callHandler(w *ResponseWriter, req Request) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = NewPanicError(p.logger, req.Method, r)
+		}
+	}()
+	return p.handler.HandleRequest(w, req)
 }
 
 func (p *Peer) sendRequestOrNotification(ctx context.Context, req requestData) error {
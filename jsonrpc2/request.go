@@ -10,6 +10,8 @@ type PendingRequest struct {
 	ctx        context.Context
 	channel    chan responseData
 	cancelFunc context.CancelFunc
+	// removed is closed once the request is out of the pending requests
+	removed chan struct{}
 }
 
 func (p PendingRequest) GetID() ID {
@@ -19,8 +21,7 @@ func (p PendingRequest) GetID() ID {
 // Cancel stops receiving further calls from the given request.
 func (p PendingRequest) Cancel() {
 	p.cancelFunc()
-	// wait for channel to close
-	<-p.channel
+	<-p.removed
 }
 
 // RecvResponse receives a response from the given request. The output parameter
@@ -30,7 +31,17 @@ func (p PendingRequest) Cancel() {
 // RecvResponse will return an [ErrContextCancel] wrapped in [RPCError] when the
 // context is canceled.
 func (p PendingRequest) RecvResponse(output any) error {
-	response, ok := <-p.channel
+	var response responseData
+	var ok bool
+	select {
+	case response, ok = <-p.channel:
+	case <-p.removed:
+		// a response delivered before the cancellation is still received
+		select {
+		case response, ok = <-p.channel:
+		default:
+		}
+	}
 	if ok {
 		defer p.cancelFunc()
 		if response.Result != nil {
@@ -49,9 +60,30 @@ func (p PendingRequest) RecvResponse(output any) error {
 }
 
 // ResponseWriter writes the response of a request. It is used to send responses back to the client.
+//
+// A handler may keep the writer to answer from another goroutine after
+// HandleRequest returned nil without writing. Writes then fail with
+// [ErrContextCancel] once the peer is shut down.
 type ResponseWriter struct {
-	id     *ID
-	output chan []byte
+	ctx     context.Context
+	id      *ID
+	output  chan []byte
+	written bool
+}
+
+// Context returns a context done when the peer shuts down.
+func (w *ResponseWriter) Context() context.Context {
+	return w.ctx
+}
+
+func (w *ResponseWriter) write(data []byte) error {
+	w.written = true
+	select {
+	case <-w.ctx.Done():
+		return ErrContextCancel
+	case w.output <- data:
+		return nil
+	}
 }
 
 func (w *ResponseWriter) WriteResponse(res any) error {
@@ -71,8 +103,7 @@ func (w *ResponseWriter) WriteResponse(res any) error {
 	if err != nil {
 		return err
 	}
-	w.output <- data
-	return nil
+	return w.write(data)
 }
 
 func (w *ResponseWriter) WriteError(res ErrorObject) error {
@@ -85,8 +116,7 @@ func (w *ResponseWriter) WriteError(res ErrorObject) error {
 	if err != nil {
 		return err
 	}
-	w.output <- data
-	return nil
+	return w.write(data)
 }
 
 type responseWriterOf[T any] struct {
@@ -12,6 +12,7 @@ var (
 	}
 	ErrObjInvalidRequest = ErrorObject{Code: JSONRPC2ErrorInvalidRequest, Message: "Invalid request."}
 	ErrObjInvalidParams  = ErrorObject{Code: JSONRPC2ErrorInvalidParams, Message: "Invalid parameters."}
+	ErrObjInternalError  = ErrorObject{Code: JSONRPC2ErrorInternalError, Message: "Internal error."}
 )
 
 // Handler is an interface for handling JSON-RPC requests. The HandleRequest
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"os"
+	"sync"
 	"testing"
 	"time"
 )
@@ -113,6 +115,76 @@ func TestNotification(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 }
 
+// TestHandlerFailure tests that a failing or panicking handler is answered with
+// an internal error and does not tear down the peer.
+func TestHandlerFailure(t *testing.T) {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	clientConn, serverConn := net.Pipe()
+	context.AfterFunc(ctx, func() {
+		clientConn.Close()
+		serverConn.Close()
+	})
+
+	// Create client and server
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewPeer(ctx, NewLineFramer(clientConn), nil)
+	server := NewPeer(ctx, NewLineFramer(serverConn), &testHandler{})
+	client.SetLogger(logger.WithGroup("client"))
+	server.SetLogger(logger.WithGroup("server"))
+
+	server.Start()
+
+	// A handler returning an error is answered with an internal error
+	var result any
+	req, err := client.Call("failMethod", nil)
+	if err != nil {
+		t.Fatalf("Client call error: %v", err)
+	}
+	err = req.RecvResponse(&result)
+	erro, ok := err.(*ErrorObject)
+	if !ok {
+		t.Fatalf("Expected *ErrorObject, got %T: %v", err, err)
+	}
+	if erro.Code != JSONRPC2ErrorInternalError {
+		t.Errorf("Expected code %d, got %d", JSONRPC2ErrorInternalError, erro.Code)
+	}
+
+	// A panicking handler is answered with an internal error and an incident ID
+	req, err = client.Call("panicMethod", nil)
+	if err != nil {
+		t.Fatalf("Client call error: %v", err)
+	}
+	err = req.RecvResponse(&result)
+	erro, ok = err.(*ErrorObject)
+	if !ok {
+		t.Fatalf("Expected *ErrorObject, got %T: %v", err, err)
+	}
+	if erro.Code != JSONRPC2ErrorInternalError {
+		t.Errorf("Expected code %d, got %d", JSONRPC2ErrorInternalError, erro.Code)
+	}
+	var data struct {
+		Incident string `json:"incident"`
+	}
+	if erro.Data == nil || json.Unmarshal(*erro.Data, &data) != nil || data.Incident == "" {
+		t.Errorf("Expected incident ID in error data, got %v", erro)
+	}
+
+	// The peer keeps serving
+	var response string
+	req, err = client.Call("testMethod", "testParams")
+	if err != nil {
+		t.Fatalf("Client call error: %v", err)
+	}
+	err = req.RecvResponse(&response)
+	if err != nil {
+		t.Fatalf("Client RecvResponse error: %v", err)
+	}
+	if response != "testResponse" {
+		t.Fatalf("Unexpected response value: %v", response)
+	}
+}
+
 // testHandler is a simple handler for testing purposes.
 type testHandler struct{}
 
@@ -122,6 +194,10 @@ func (h *testHandler) HandleRequest(w *ResponseWriter, req Request) error {
 		return w.WriteResponse("testResponse")
 	case "errorMethod":
 		return w.WriteError(ErrorObject{Code: JSONRPC2ErrorInternalError, Message: "Internal error"})
+	case "failMethod":
+		return fmt.Errorf("handler failed")
+	case "panicMethod":
+		panic("handler panicked")
 	case "notifyMethod":
 		var param string
 		err := json.Unmarshal(*req.Params, &param)
@@ -200,3 +276,244 @@ func (h *slowTestHandler) HandleRequest(w *ResponseWriter, req Request) error {
 	}
 	return w.WriteError(ErrorObject{Code: JSONRPC2ErrorMethodNotFound, Message: "Method not found"})
 }
+
+// TestMalformedFrames verifies that malformed frames are answered with errors
+// without ending the session, until too many arrive in a row.
+func TestMalformedFrames(t *testing.T) {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	clientConn, serverConn := net.Pipe()
+	context.AfterFunc(ctx, func() {
+		clientConn.Close()
+		serverConn.Close()
+	})
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	server := NewPeer(ctx, NewLineFramer(serverConn), &testHandler{})
+	server.SetLogger(logger.WithGroup("server"))
+	server.SetMaxMalformedFrames(3)
+	server.Start()
+
+	client := NewLineFramer(clientConn)
+	roundTrip := func(t *testing.T, frame string) responseData {
+		t.Helper()
+		if err := client.WriteFrame([]byte(frame)); err != nil {
+			t.Fatalf("WriteFrame error: %v", err)
+		}
+		data, err := client.ReadFrame()
+		if err != nil {
+			t.Fatalf("ReadFrame error: %v", err)
+		}
+		var response responseData
+		if err := json.Unmarshal(data, &response); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		return response
+	}
+
+	tests := []struct {
+		name  string
+		frame string
+		code  int
+		id    *ID
+	}{
+		{"ParseError", `{"jsonrpc": "2.0", "method"`, JSONRPC2ErrorParseError, nil},
+		{"Batch", `[]`, JSONRPC2ErrorInvalidRequest, nil},
+		{"Version", `{"jsonrpc": "1.0", "method": "testMethod", "id": 7}`, JSONRPC2ErrorInvalidRequest, &ID{number: 7}},
+		{"ResultAndError", `{"jsonrpc": "2.0", "result": 1, "error": {"code": 1, "message": "x"}, "id": "a"}`, JSONRPC2ErrorInvalidRequest, &ID{name: "a"}},
+		{"MissingMethod", `{"jsonrpc": "2.0", "params": {}, "id": 8}`, JSONRPC2ErrorInvalidRequest, &ID{number: 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := roundTrip(t, tt.frame)
+			if response.Error == nil || response.Error.Code != tt.code {
+				t.Fatalf("Expected error code %d, got %+v", tt.code, response)
+			}
+			if (tt.id == nil) != (response.ID == nil) || (tt.id != nil && *tt.id != *response.ID) {
+				t.Errorf("Expected id %v, got %v", tt.id, response.ID)
+			}
+
+			// a valid request keeps being served and resets the counter
+			response = roundTrip(t, `{"jsonrpc": "2.0", "method": "testMethod", "id": 1}`)
+			if response.Error != nil {
+				t.Fatalf("Unexpected error: %v", response.Error)
+			}
+		})
+	}
+
+	t.Run("TooManyMalformedFrames", func(t *testing.T) {
+		for range 2 {
+			roundTrip(t, `not json`)
+		}
+		// the third malformed frame in a row ends the session
+		if err := client.WriteFrame([]byte(`not json`)); err != nil {
+			t.Fatalf("WriteFrame error: %v", err)
+		}
+		if _, err := client.ReadFrame(); err == nil {
+			t.Error("Expected the session to be closed")
+		}
+	})
+}
+
+// TestCallOf tests typed calls bound to a context.
+func TestCallOf(t *testing.T) {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	clientConn, serverConn := net.Pipe()
+	context.AfterFunc(ctx, func() {
+		clientConn.Close()
+		serverConn.Close()
+	})
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewPeer(ctx, NewLineFramer(clientConn), nil)
+	server := NewPeer(ctx, NewLineFramer(serverConn), &slowTestHandler{})
+	client.SetLogger(logger.WithGroup("client"))
+	server.SetLogger(logger.WithGroup("server"))
+
+	server.Start()
+
+	// The call is abandoned when its context expires
+	callCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := CallOf[any, string](callCtx, client, "slowMethod", nil)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("Call was not abandoned on timeout, took %v", elapsed)
+	}
+	client.mutex.Lock()
+	pending := len(client.pendingRequests)
+	client.mutex.Unlock()
+	if pending != 0 {
+		t.Errorf("Expected no pending requests, got %d", pending)
+	}
+
+	// The result is decoded into the result type
+	result, err := CallOf[any, string](ctx, client, "slowMethod", nil)
+	if err != nil {
+		t.Fatalf("CallOf error: %v", err)
+	}
+	if result != "response" {
+		t.Fatalf("Unexpected response value: %v", result)
+	}
+}
+
+// TestResponseAtCancellation delivers responses while their calls are
+// canceled, which must neither panic nor lose a delivered response.
+func TestResponseAtCancellation(t *testing.T) {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	clientConn, serverConn := net.Pipe()
+	context.AfterFunc(ctx, func() {
+		clientConn.Close()
+		serverConn.Close()
+	})
+	go io.Copy(io.Discard, serverConn)
+	client := NewPeer(ctx, NewLineFramer(clientConn), nil)
+
+	result := json.RawMessage(`"response"`)
+	for range 500 {
+		callCtx, cancel := context.WithCancel(ctx)
+		req, err := client.CallContext(callCtx, "method", nil)
+		if err != nil {
+			t.Fatalf("CallContext error: %v", err)
+		}
+		id := req.GetID()
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			cancel()
+		}()
+		go func() {
+			defer wg.Done()
+			client.deliver(id, responseData{ID: &id, Result: &result})
+		}()
+		var got string
+		err = req.RecvResponse(&got)
+		wg.Wait()
+		if err == nil && got != "response" {
+			t.Fatalf("Unexpected response value: %v", got)
+		}
+		if err != nil && err != (RPCError{ErrContextCancel}) {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// a late response is dropped
+		client.deliver(id, responseData{ID: &id, Result: &result})
+	}
+}
+
+// TestIDGenerators tests concurrent calls with different ID strategies.
+func TestIDGenerators(t *testing.T) {
+	generators := map[string]IDGenerator{
+		"Number":   new(NumberIDGenerator),
+		"UUID":     UUIDGenerator{},
+		"Prefixed": NewPrefixedIDGenerator("upstream-"),
+	}
+	for name, generator := range generators {
+		t.Run(name, func(t *testing.T) {
+			ctx, stop := context.WithCancel(context.Background())
+			defer stop()
+			clientConn, serverConn := net.Pipe()
+			context.AfterFunc(ctx, func() {
+				clientConn.Close()
+				serverConn.Close()
+			})
+
+			logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			client := NewPeer(ctx, NewLineFramer(clientConn), nil)
+			server := NewPeer(ctx, NewLineFramer(serverConn), &testHandler{})
+			client.SetLogger(logger.WithGroup("client"))
+			server.SetLogger(logger.WithGroup("server"))
+			client.SetIDGenerator(generator)
+
+			server.Start()
+
+			var wg sync.WaitGroup
+			for range 20 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					result, err := CallOf[string, string](ctx, client, "testMethod", "testParams")
+					if err != nil {
+						t.Errorf("CallOf error: %v", err)
+					} else if result != "testResponse" {
+						t.Errorf("Unexpected response value: %v", result)
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+// TestIDMarshal tests the JSON encoding of string and numeric IDs.
+func TestIDMarshal(t *testing.T) {
+	tests := []struct {
+		id   ID
+		json string
+	}{
+		{MakeNumberID(42), `42`},
+		{MakeNumberID(1 << 40), `1099511627776`},
+		{MakeStringID("upstream-1"), `"upstream-1"`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.id)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		if string(data) != tt.json {
+			t.Errorf("Expected %s, got %s", tt.json, data)
+		}
+		var id ID
+		if err := json.Unmarshal(data, &id); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		if id != tt.id {
+			t.Errorf("Expected %v, got %v", tt.id, id)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+// LLMRequest is a sampling request as passed to a model.
+type LLMRequest struct {
+	// name of the model, as selected by the ModelSelector of the provider if any
+	Model         string
+	Messages      []SamplingMessageItem
+	SystemPrompt  string
+	MaxTokens     int64
+	StopSequences []string
+	Temperature   *float64
+}
+
+// LLMResponse is the content generated by a model.
+type LLMResponse struct {
+	Content Content
+	// StopReasonEndTurn, StopReasonStopSequence, StopReasonMaxTokens or a
+	// reason specific to the model
+	StopReason string
+	// name of the model used, defaults to LLMRequest.Model
+	Model string
+}
+
+// LLM is the interface of the models answering sampling requests.
+type LLM interface {
+	Generate(ctx context.Context, req LLMRequest) (LLMResponse, error)
+}
+
+// DefaultLLMTimeout bounds the generation of a response by an
+// [LLMSamplingProvider] without Timeout.
+var DefaultLLMTimeout time.Duration = 2 * time.Minute
+
+// LLMSamplingProvider is a CapSamplingProvider answering sampling requests with
+// an LLM. As a [CapSamplingCreator], it generates responses in their own
+// goroutines, which are cancelled when the session ends.
+type LLMSamplingProvider struct {
+	LLM LLM
+	// Selector picks the model of requests from their model preferences, if
+	// not nil.
+	Selector *ModelSelector
+	// Timeout bounds the generation of a response, DefaultLLMTimeout if not
+	// positive.
+	Timeout time.Duration
+}
+
+func NewLLMSamplingProvider(llm LLM) *LLMSamplingProvider {
+	return &LLMSamplingProvider{LLM: llm}
+}
+
+func (p *LLMSamplingProvider) Sampling_Capability() *CapSampling {
+	return new(CapSampling)
+}
+
+// HandleRequest serves the callers not supporting [CapSamplingCreator], with
+// a context that is only done at the timeout.
+func (p *LLMSamplingProvider) HandleRequest(w jsonrpc2.ResponseWriterOf[SamplingResponse], msg SamplingMessage) error {
+	res, err := p.Sampling_OnCreateMessage(context.Background(), msg)
+	if err != nil {
+		return err
+	}
+	return w.WriteResponse(res)
+}
+
+func (p *LLMSamplingProvider) Sampling_OnCreateMessage(ctx context.Context, msg SamplingMessage) (SamplingResponse, error) {
+	req := LLMRequest{
+		Messages:      msg.Messages,
+		SystemPrompt:  msg.SystemPrompt,
+		MaxTokens:     msg.MaxTokens,
+		StopSequences: msg.StopSequences,
+		Temperature:   msg.Temperature,
+	}
+	if p.Selector != nil {
+		if model, ok := p.Selector.Select(msg.ModelPreferences); ok {
+			req.Model = model.Name
+		}
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultLLMTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	res, err := p.LLM.Generate(ctx, req)
+	if err != nil {
+		return SamplingResponse{}, err
+	}
+	if res.Model == "" {
+		res.Model = req.Model
+	}
+	return SamplingResponse{
+		Role:       RoleAssistant,
+		Content:    res.Content,
+		Model:      res.Model,
+		StopReason: res.StopReason,
+	}, nil
+}
+
+var ErrScriptExhausted = errors.New("mcp: scripted LLM has no more responses")
+
+// ScriptedLLM is a deterministic LLM replaying responses in order, to test
+// sampling flows offline. Text responses without a stop reason are cut at the
+// first stop sequence of the request, as a model would.
+type ScriptedLLM struct {
+	mutex     sync.Mutex
+	responses []LLMResponse
+	requests  []LLMRequest
+}
+
+func NewScriptedLLM(responses ...LLMResponse) *ScriptedLLM {
+	return &ScriptedLLM{responses: responses}
+}
+
+// Add appends responses to the script.
+func (l *ScriptedLLM) Add(responses ...LLMResponse) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.responses = append(l.responses, responses...)
+}
+
+// Requests returns the requests received so far.
+func (l *ScriptedLLM) Requests() []LLMRequest {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]LLMRequest(nil), l.requests...)
+}
+
+// Generate returns the next response of the script, or ErrScriptExhausted.
+func (l *ScriptedLLM) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return LLMResponse{}, err
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.requests = append(l.requests, req)
+	if len(l.responses) == 0 {
+		return LLMResponse{}, ErrScriptExhausted
+	}
+	res := l.responses[0]
+	l.responses = l.responses[1:]
+
+	if res.StopReason == "" {
+		res.StopReason = StopReasonEndTurn
+		if res.Content.Type == ContentTypeText {
+			for _, stop := range req.StopSequences {
+				if i := strings.Index(res.Content.Text, stop); stop != "" && i >= 0 {
+					res.Content.Text = res.Content.Text[:i]
+					res.StopReason = StopReasonStopSequence
+				}
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+// blockingLLM generates until its context is done.
+type blockingLLM struct {
+	started chan struct{}
+	done    chan error
+}
+
+func (l *blockingLLM) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	close(l.started)
+	<-ctx.Done()
+	l.done <- ctx.Err()
+	return LLMResponse{}, ctx.Err()
+}
+
+func TestLLMSamplingProvider(t *testing.T) {
+	llm := NewScriptedLLM(
+		LLMResponse{Content: TextContent("It is sunny.\n\nAnything else?")},
+		LLMResponse{Content: TextContent("truncated"), StopReason: StopReasonMaxTokens, Model: "claude-3-opus"},
+	)
+	provider := NewLLMSamplingProvider(llm)
+	provider.Selector = NewModelSelector(
+		ModelInfo{Name: "claude-3-5-sonnet", IntelligenceScore: 0.8},
+		ModelInfo{Name: "claude-3-haiku", SpeedScore: 0.9},
+	)
+
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+	}
+	clientInstance := &ClientImpl{
+		CapSamplingProvider: provider,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	msg := SamplingMessage{
+		Messages:         []SamplingMessageItem{{Role: RoleUser, Content: TextContent("How is the weather?")}},
+		ModelPreferences: &SamplingMessageModelPreference{SpeedPriority: Priority(1)},
+		SystemPrompt:     "Be brief.",
+		MaxTokens:        50,
+		StopSequences:    []string{"\n\n"},
+	}
+
+	t.Run("StopSequence", func(t *testing.T) {
+		response, err := ts.Server.CreateMessage(ts.Ctx, msg)
+		if err != nil {
+			t.Fatalf("CreateMessage failed: %v", err)
+		}
+		if response.Role != RoleAssistant || response.Content.Text != "It is sunny." ||
+			response.StopReason != StopReasonStopSequence || response.Model != "claude-3-haiku" {
+			t.Errorf("Unexpected response: %+v", response)
+		}
+		req := llm.Requests()[0]
+		if req.Model != "claude-3-haiku" || req.SystemPrompt != "Be brief." || req.MaxTokens != 50 ||
+			len(req.Messages) != 1 || req.Messages[0].Content.Text != "How is the weather?" {
+			t.Errorf("Unexpected request: %+v", req)
+		}
+	})
+
+	t.Run("ScriptedStopReason", func(t *testing.T) {
+		response, err := ts.Server.CreateMessage(ts.Ctx, msg)
+		if err != nil {
+			t.Fatalf("CreateMessage failed: %v", err)
+		}
+		if response.Content.Text != "truncated" || response.StopReason != StopReasonMaxTokens || response.Model != "claude-3-opus" {
+			t.Errorf("Unexpected response: %+v", response)
+		}
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		_, err := ts.Server.CreateMessage(ts.Ctx, msg)
+		if _, ok := err.(*jsonrpc2.ErrorObject); !ok {
+			t.Errorf("Expected an error object, got %v", err)
+		}
+		if len(llm.Requests()) != 3 {
+			t.Errorf("Expected 3 requests, got %d", len(llm.Requests()))
+		}
+	})
+
+	t.Run("SessionEnd", func(t *testing.T) {
+		blocking := &blockingLLM{started: make(chan struct{}), done: make(chan error, 1)}
+		provider.LLM = blocking
+		go ts.Server.CreateMessage(ts.Ctx, msg)
+		<-blocking.started
+		ts.Client.Close()
+		select {
+		case err := <-blocking.done:
+			if err != context.Canceled {
+				t.Errorf("Expected context.Canceled, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Error("Timeout waiting for the generation to be canceled")
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		llm.Add(LLMResponse{Content: TextContent("unused")})
+		if _, err := llm.Generate(ctx, LLMRequest{}); err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
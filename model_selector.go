@@ -0,0 +1,83 @@
+package mcp
+
+import "strings"
+
+// ModelInfo describes a model available to a client for sampling. Scores
+// range from 0 to 1, higher being better: a CostScore of 1 is the cheapest
+// model, a SpeedScore of 1 the fastest and an IntelligenceScore of 1 the most
+// capable.
+type ModelInfo struct {
+	Name              string
+	CostScore         float64
+	SpeedScore        float64
+	IntelligenceScore float64
+}
+
+// ModelSelector maps the model preferences of sampling requests onto a list of
+// models configured by the client.
+type ModelSelector struct {
+	// Models in order of preference, the first one being the default.
+	Models []ModelInfo
+}
+
+func NewModelSelector(models ...ModelInfo) *ModelSelector {
+	return &ModelSelector{Models: models}
+}
+
+// Select returns the model best matching prefs, or false if no model is
+// configured.
+//
+// Hints are considered in order: the first hint whose name is a substring of
+// the name of some models, ignoring case, restricts the choice to those models.
+// The model with the highest scores weighted by the priorities of prefs is
+// then selected, the earliest one on ties.
+func (s *ModelSelector) Select(prefs *SamplingMessageModelPreference) (ModelInfo, bool) {
+	if len(s.Models) == 0 {
+		return ModelInfo{}, false
+	}
+	if prefs == nil {
+		return s.Models[0], true
+	}
+
+	candidates := s.Models
+	for _, hint := range prefs.Hints {
+		if matched := s.matchHint(hint.Name); len(matched) > 0 {
+			candidates = matched
+			break
+		}
+	}
+
+	best, bestScore := candidates[0], weightedScore(candidates[0], prefs)
+	for _, model := range candidates[1:] {
+		if score := weightedScore(model, prefs); score > bestScore {
+			best, bestScore = model, score
+		}
+	}
+	return best, true
+}
+
+func (s *ModelSelector) matchHint(name string) []ModelInfo {
+	if name == "" {
+		return nil
+	}
+	name = strings.ToLower(name)
+	var matched []ModelInfo
+	for _, model := range s.Models {
+		if strings.Contains(strings.ToLower(model.Name), name) {
+			matched = append(matched, model)
+		}
+	}
+	return matched
+}
+
+func weightedScore(model ModelInfo, prefs *SamplingMessageModelPreference) float64 {
+	priority := func(p *float64) float64 {
+		if p == nil {
+			return 0
+		}
+		return *p
+	}
+	return model.CostScore*priority(prefs.CostPriority) +
+		model.SpeedScore*priority(prefs.SpeedPriority) +
+		model.IntelligenceScore*priority(prefs.IntelligencePriority)
+}
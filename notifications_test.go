@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+func TestClientNotifications(t *testing.T) {
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapPromptsProvider:   serverProvider,
+		CapToolsProvider:     serverProvider,
+		CapResourcesProvider: serverProvider,
+	}
+	clientProvider := &testClientImpl{roots_ListChanged: make(chan struct{})}
+	clientInstance := &ClientImpl{
+		CapRootsProvider:    clientProvider,
+		CapSamplingProvider: clientProvider,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	received := make(chan string, 10)
+	ts.Client.OnToolsListChanged(func() {
+		// callbacks may call the server
+		if _, err := ts.Client.ToolsList(ts.Ctx, ""); err != nil {
+			t.Errorf("ToolsList failed: %v", err)
+		}
+		received <- kMethodToolsListChanged
+	})
+	ts.Client.OnPromptsListChanged(func() { received <- kMethodPromptsListChanged })
+	ts.Client.OnResourcesListChanged(func() { received <- kMethodResourcesListChanged })
+	ts.Client.OnResourceUpdated(func(uri string) { received <- kMethodResourcesUpdated + " " + uri })
+	ts.Client.OnLogMessage(func(msg LogMessageNotification) {
+		received <- kMethodLoggingMessage + " " + msg.Level + " " + string(msg.Data)
+	})
+	ts.Client.OnProgress(func(msg ProgressNotification) {
+		data, _ := json.Marshal(msg)
+		received <- kMethodProgress + " " + string(data)
+	})
+
+	expect := func(t *testing.T, expected string) {
+		t.Helper()
+		select {
+		case got := <-received:
+			if got != expected {
+				t.Errorf("Expected %q, got %q", expected, got)
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatalf("Timeout waiting for %q", expected)
+		}
+	}
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("ListChanged", func(t *testing.T) {
+		serverProvider.tools_ListChanged <- struct{}{}
+		expect(t, kMethodToolsListChanged)
+		serverProvider.prompts_ListChanged <- struct{}{}
+		expect(t, kMethodPromptsListChanged)
+		serverProvider.resources_ListChanged <- struct{}{}
+		expect(t, kMethodResourcesListChanged)
+	})
+
+	t.Run("ResourceUpdated", func(t *testing.T) {
+		if err := ts.Server.NotifyResourceUpdated(ts.Ctx, "resource://test/0"); err != nil {
+			t.Fatalf("NotifyResourceUpdated failed: %v", err)
+		}
+		expect(t, kMethodResourcesUpdated+" resource://test/0")
+	})
+
+	t.Run("LogMessage", func(t *testing.T) {
+		msg := LogMessageNotification{Level: "info", Data: json.RawMessage(`"hello"`)}
+		if err := ts.Server.NotifyLogMessage(ts.Ctx, msg); err != nil {
+			t.Fatalf("NotifyLogMessage failed: %v", err)
+		}
+		expect(t, kMethodLoggingMessage+` info "hello"`)
+	})
+
+	t.Run("Progress", func(t *testing.T) {
+		msg := ProgressNotification{ProgressToken: jsonrpc2.MakeStringID("token"), Progress: 1, Total: 2, Message: "half way"}
+		if err := ts.Server.NotifyProgress(ts.Ctx, msg); err != nil {
+			t.Fatalf("NotifyProgress failed: %v", err)
+		}
+		expect(t, kMethodProgress+` {"progressToken":"token","progress":1,"total":2,"message":"half way"}`)
+	})
+
+	t.Run("RequestsStillWork", func(t *testing.T) {
+		if _, err := ts.Client.ToolCall(ts.Ctx, "test_tool", map[string]string{"param1": "value1"}); err != nil {
+			t.Fatalf("ToolCall failed: %v", err)
+		}
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		hold := make(chan struct{})
+		remove := ts.Client.OnResourceUpdated(func(uri string) {
+			if uri == "hold" {
+				<-hold
+			}
+		})
+		defer remove()
+		if err := ts.Server.NotifyResourceUpdated(ts.Ctx, "hold"); err != nil {
+			t.Fatalf("NotifyResourceUpdated failed: %v", err)
+		}
+		expect(t, kMethodResourcesUpdated+" hold")
+
+		// the callbacks are held: the queue fills up, and further
+		// notifications are dropped or coalesced without blocking the client
+		msg := LogMessageNotification{Level: "info", Data: json.RawMessage(`"flood"`)}
+		for range DefaultNotificationQueueSize + 10 {
+			if err := ts.Server.NotifyLogMessage(ts.Ctx, msg); err != nil {
+				t.Fatalf("NotifyLogMessage failed: %v", err)
+			}
+		}
+		for range 2 {
+			if err := ts.Server.NotifyToolsListChanged(ts.Ctx); err != nil {
+				t.Fatalf("NotifyToolsListChanged failed: %v", err)
+			}
+		}
+		if _, err := ts.Client.ToolCall(ts.Ctx, "test_tool", map[string]string{"param1": "value1"}); err != nil {
+			t.Fatalf("ToolCall failed while callbacks are held: %v", err)
+		}
+
+		close(hold)
+		logs, listChanged := 0, 0
+		timeout := time.After(2 * time.Second)
+		for logs < DefaultNotificationQueueSize || listChanged == 0 {
+			select {
+			case got := <-received:
+				switch got {
+				case kMethodLoggingMessage + ` info "flood"`:
+					logs++
+				case kMethodToolsListChanged:
+					listChanged++
+				}
+			case <-timeout:
+				t.Fatalf("Timeout, got %d log messages and %d list changes", logs, listChanged)
+			}
+		}
+		select {
+		case got := <-received:
+			t.Errorf("Expected the overflow dropped or coalesced, got %q", got)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package mcp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"iter"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+// PageOption configures the iterators of [ClientState] over paginated lists.
+type PageOption func(*pageOptions)
+
+type pageOptions struct {
+	maxPages int
+}
+
+// WithMaxPages stops iterating after n pages. A value of 0 or less means no
+// limit.
+func WithMaxPages(n int) PageOption {
+	return func(o *pageOptions) {
+		o.maxPages = n
+	}
+}
+
+// allPages returns an iterator over the items of the pages returned by fetch,
+// following their cursors until a page has no next cursor, or its next cursor
+// was already seen. Iteration stops at the first error, which is yielded with a
+// zero item.
+func allPages[T any](ctx context.Context, opts []PageOption, fetch func(ctx context.Context, cursor string) ([]T, string, error)) iter.Seq2[T, error] {
+	var o pageOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(yield func(T, error) bool) {
+		seen := make(map[string]bool)
+		cursor := ""
+		for pages := 1; ; pages++ {
+			items, next, err := fetch(ctx, cursor)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" || seen[next] || (o.maxPages > 0 && pages >= o.maxPages) {
+				return
+			}
+			seen[next] = true
+			cursor = next
+		}
+	}
+}
+
+// AllTools returns an iterator over the tools of the server across all pages.
+func (c *ClientState) AllTools(ctx context.Context, opts ...PageOption) iter.Seq2[ToolSpec, error] {
+	return allPages(ctx, opts, func(ctx context.Context, cursor string) ([]ToolSpec, string, error) {
+		pages, err := c.ToolsList(ctx, cursor)
+		var tools []ToolSpec
+		next := ""
+		for _, page := range pages {
+			tools = append(tools, page.Tools...)
+			next = page.NextCursor
+		}
+		return tools, next, err
+	})
+}
+
+// AllPrompts returns an iterator over the prompts of the server across all
+// pages.
+func (c *ClientState) AllPrompts(ctx context.Context, opts ...PageOption) iter.Seq2[PromptSpec, error] {
+	return allPages(ctx, opts, func(ctx context.Context, cursor string) ([]PromptSpec, string, error) {
+		pages, err := c.PromptsList(ctx, cursor)
+		var prompts []PromptSpec
+		next := ""
+		for _, page := range pages {
+			prompts = append(prompts, page.Prompts...)
+			next = page.NextCursor
+		}
+		return prompts, next, err
+	})
+}
+
+// AllResources returns an iterator over the resources of the server across
+// all pages.
+func (c *ClientState) AllResources(ctx context.Context, opts ...PageOption) iter.Seq2[ResourceSpec, error] {
+	return allPages(ctx, opts, func(ctx context.Context, cursor string) ([]ResourceSpec, string, error) {
+		page, err := c.ResourcesList(ctx, cursor)
+		return page.Resources, page.NextCursor, err
+	})
+}
+
+// AllResourceTemplates returns an iterator over the resource templates of the
+// server across all pages.
+func (c *ClientState) AllResourceTemplates(ctx context.Context, opts ...PageOption) iter.Seq2[ResourceTemplateSpec, error] {
+	return allPages(ctx, opts, func(ctx context.Context, cursor string) ([]ResourceTemplateSpec, string, error) {
+		page, err := c.ResourcesTemplatesList(ctx, cursor)
+		return page.ResourceTemplates, page.NextCursor, err
+	})
+}
+
+// collect gathers the items of seq, stopping at the first error.
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// DefaultPageSize is the page size of a [Paginator] created with a size of 0.
+var DefaultPageSize = 100
+
+// Paginator splits lists served by a provider into pages addressed by opaque
+// cursors. Cursors are signed, so that a client cannot forge or alter them.
+type Paginator struct {
+	pageSize int
+	key      []byte
+}
+
+// NewPaginator returns a Paginator serving pages of pageSize items, with
+// cursors signed by a random key. Cursors are therefore only valid for the
+// lifetime of the Paginator.
+func NewPaginator(pageSize int) *Paginator {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return NewPaginatorWithKey(pageSize, key)
+}
+
+// NewPaginatorWithKey returns a Paginator serving pages of pageSize items, with
+// cursors signed by key. Servers sharing the key accept each other's cursors.
+func NewPaginatorWithKey(pageSize int, key []byte) *Paginator {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return &Paginator{pageSize: pageSize, key: key}
+}
+
+// Paginate returns the page of items addressed by cursor, the first page for
+// an empty cursor, and the cursor of the next page, empty for the last one. An
+// invalid cursor is reported with [jsonrpc2.ErrObjInvalidParams].
+func Paginate[T any](p *Paginator, items []T, cursor string) ([]T, string, *jsonrpc2.ErrorObject) {
+	offset := 0
+	if cursor != "" {
+		decoded, ok := p.decodeCursor(cursor)
+		if !ok || decoded > uint64(len(items)) {
+			return nil, "", errObj(jsonrpc2.ErrObjInvalidParams)
+		}
+		offset = int(decoded)
+	}
+
+	end := min(offset+p.pageSize, len(items))
+	next := ""
+	if end < len(items) {
+		next = p.encodeCursor(end)
+	}
+	return items[offset:end], next, nil
+}
+
+// encodeCursor encodes offset followed by its signature.
+func (p *Paginator) encodeCursor(offset int) string {
+	data := binary.AppendUvarint(nil, uint64(offset))
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write(data)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(data))
+}
+
+func (p *Paginator) decodeCursor(cursor string) (uint64, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	offset, n := binary.Uvarint(raw)
+	if n <= 0 {
+		return 0, false
+	}
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write(raw[:n])
+	if !hmac.Equal(mac.Sum(nil), raw[n:]) {
+		return 0, false
+	}
+	return offset, true
+}
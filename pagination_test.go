@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestPaginator(t *testing.T) {
+	items := make([]int, 250)
+	for i := range items {
+		items[i] = i
+	}
+	p := NewPaginator(100)
+
+	var all []int
+	var cursors []string
+	cursor := ""
+	for {
+		page, next, erro := Paginate(p, items, cursor)
+		if erro != nil {
+			t.Fatalf("Paginate failed: %v", erro)
+		}
+		all = append(all, page...)
+		if next == "" {
+			break
+		}
+		cursors = append(cursors, next)
+		cursor = next
+	}
+	if len(all) != len(items) || all[249] != 249 {
+		t.Fatalf("Expected all %d items, got %d", len(items), len(all))
+	}
+	if len(cursors) != 2 {
+		t.Fatalf("Expected 2 cursors, got %d", len(cursors))
+	}
+
+	t.Run("TamperedCursor", func(t *testing.T) {
+		raw := []byte(cursors[0])
+		raw[0] ^= 1
+		if _, _, erro := Paginate(p, items, string(raw)); erro == nil {
+			t.Error("Expected error for tampered cursor")
+		}
+		if _, _, erro := Paginate(p, items, "not a cursor"); erro == nil {
+			t.Error("Expected error for invalid cursor")
+		}
+	})
+
+	t.Run("ForeignCursor", func(t *testing.T) {
+		if _, _, erro := Paginate(NewPaginator(100), items, cursors[0]); erro == nil {
+			t.Error("Expected error for cursor signed by another key")
+		}
+		key := []byte("shared key")
+		cursor := NewPaginatorWithKey(10, key).encodeCursor(10)
+		page, _, erro := Paginate(NewPaginatorWithKey(10, key), items, cursor)
+		if erro != nil || page[0] != 10 {
+			t.Errorf("Expected cursor shared by key to be accepted, got %v, %v", page, erro)
+		}
+	})
+}
+
+func TestClientIterators(t *testing.T) {
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapPromptsProvider:   serverProvider,
+		CapToolsProvider:     serverProvider,
+		CapResourcesProvider: serverProvider,
+	}
+	// serve 25 tools on pages of 10
+	var tools []ToolSpec
+	for i := range 25 {
+		tools = append(tools, ToolSpec{Name: fmt.Sprintf("tool_%d", i)})
+	}
+	paginator := NewPaginator(10)
+	serverInstance.Use(func(ctx context.Context, inv *Invocation, next MethodHandler) (any, error) {
+		if inv.Method != kMethodToolsList {
+			return next(ctx, inv)
+		}
+		page, cursor, erro := Paginate(paginator, tools, inv.Params.(*PagedRequest).Cursor)
+		if erro != nil {
+			return nil, erro
+		}
+		return []ListToolsResonponse{{Tools: page, NextCursor: cursor}}, nil
+	})
+
+	clientProvider := &testClientImpl{roots_ListChanged: make(chan struct{})}
+	clientInstance := &ClientImpl{
+		CapRootsProvider:    clientProvider,
+		CapSamplingProvider: clientProvider,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("AllTools", func(t *testing.T) {
+		var names []string
+		for tool, err := range ts.Client.AllTools(ts.Ctx) {
+			if err != nil {
+				t.Fatalf("AllTools failed: %v", err)
+			}
+			names = append(names, tool.Name)
+		}
+		if len(names) != 25 || names[24] != "tool_24" {
+			t.Errorf("Unexpected tools: %v", names)
+		}
+	})
+
+	t.Run("MaxPages", func(t *testing.T) {
+		count := 0
+		for _, err := range ts.Client.AllTools(ts.Ctx, WithMaxPages(2)) {
+			if err != nil {
+				t.Fatalf("AllTools failed: %v", err)
+			}
+			count++
+		}
+		if count != 20 {
+			t.Errorf("Expected 20 tools, got %d", count)
+		}
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		count := 0
+		for range ts.Client.AllTools(ts.Ctx) {
+			count++
+			if count == 3 {
+				break
+			}
+		}
+		if count != 3 {
+			t.Errorf("Expected 3 tools, got %d", count)
+		}
+	})
+
+	t.Run("OtherLists", func(t *testing.T) {
+		prompts, err := collect(ts.Client.AllPrompts(ts.Ctx))
+		if err != nil || len(prompts) != 1 {
+			t.Errorf("Unexpected prompts: %v, %v", prompts, err)
+		}
+		resources, err := collect(ts.Client.AllResources(ts.Ctx))
+		if err != nil || len(resources) != 1 {
+			t.Errorf("Unexpected resources: %v, %v", resources, err)
+		}
+		templates, err := collect(ts.Client.AllResourceTemplates(ts.Ctx))
+		if err != nil || len(templates) != 1 {
+			t.Errorf("Unexpected templates: %v, %v", templates, err)
+		}
+	})
+}
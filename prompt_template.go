@@ -0,0 +1,179 @@
+package mcp
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+// TemplatePrompt is a prompt whose messages are [text/template] templates
+// rendered with the arguments of prompts/get. Arguments are available to the
+// templates as fields of the dot, like {{.question}}, and optional arguments
+// that were not given are empty.
+//
+// It is created with a [PromptBuilder].
+type TemplatePrompt struct {
+	spec     PromptSpec
+	messages []messageTemplate
+}
+
+type messageTemplate struct {
+	role string
+	text *template.Template
+}
+
+// PromptBuilder builds a [TemplatePrompt]. Errors are reported by Build.
+type PromptBuilder struct {
+	prompt TemplatePrompt
+	err    error
+}
+
+func NewPromptBuilder(name string, description string) *PromptBuilder {
+	return &PromptBuilder{prompt: TemplatePrompt{spec: PromptSpec{Name: name, Description: description}}}
+}
+
+// Argument declares an argument of the prompt.
+func (b *PromptBuilder) Argument(name string, description string, required bool) *PromptBuilder {
+	b.prompt.spec.Arguments = append(b.prompt.spec.Arguments, ArgumentSpec{
+		Name:        name,
+		Description: description,
+		Required:    required,
+	})
+	return b
+}
+
+// Message appends a message of the given role, "user" or "assistant", whose
+// text is rendered from the template text.
+func (b *PromptBuilder) Message(role string, text string) *PromptBuilder {
+	name := b.prompt.spec.Name + "/" + role
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		b.err = errors.Join(b.err, err)
+		return b
+	}
+	b.prompt.messages = append(b.prompt.messages, messageTemplate{role: role, text: tmpl})
+	return b
+}
+
+// Build returns the prompt, or the errors of the templates that failed to
+// parse.
+func (b *PromptBuilder) Build() (*TemplatePrompt, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	prompt := b.prompt
+	return &prompt, nil
+}
+
+// Spec returns the specification of the prompt listed by prompts/list.
+func (p *TemplatePrompt) Spec() PromptSpec {
+	return p.spec
+}
+
+// Render renders the messages of the prompt with args. Missing required
+// arguments are reported with [jsonrpc2.ErrObjInvalidParams], and templates
+// failing to execute with [jsonrpc2.ErrObjInternalError].
+func (p *TemplatePrompt) Render(args map[string]string) (PromptGetResponse, *jsonrpc2.ErrorObject) {
+	if erro := checkPromptArguments(p.spec, args); erro != nil {
+		return PromptGetResponse{}, erro
+	}
+	data := make(map[string]string, len(p.spec.Arguments))
+	for _, arg := range p.spec.Arguments {
+		data[arg.Name] = args[arg.Name]
+	}
+
+	response := PromptGetResponse{Description: p.spec.Description}
+	for _, msg := range p.messages {
+		var text strings.Builder
+		if err := msg.text.Execute(&text, data); err != nil {
+			return PromptGetResponse{}, errObj(jsonrpc2.ErrObjInternalError)
+		}
+		response.Messages = append(response.Messages, MessageWithRole{
+			Role:    msg.role,
+			Content: TextContent(text.String()),
+		})
+	}
+	return response, nil
+}
+
+// TemplatePromptProvider is a [CapPromptsProvider] serving template prompts.
+type TemplatePromptProvider struct {
+	started sync.Once
+
+	mutex   sync.RWMutex
+	prompts []*TemplatePrompt
+}
+
+func NewTemplatePromptProvider(prompts ...*TemplatePrompt) *TemplatePromptProvider {
+	return &TemplatePromptProvider{prompts: prompts}
+}
+
+// Add adds prompts to the provider, replacing the prompts of the same names.
+func (p *TemplatePromptProvider) Add(prompts ...*TemplatePrompt) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, prompt := range prompts {
+		if i := p.indexOf(prompt.spec.Name); i >= 0 {
+			p.prompts[i] = prompt
+		} else {
+			p.prompts = append(p.prompts, prompt)
+		}
+	}
+}
+
+func (p *TemplatePromptProvider) indexOf(name string) int {
+	for i, prompt := range p.prompts {
+		if prompt.spec.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *TemplatePromptProvider) Prompts_Started() *sync.Once {
+	return &p.started
+}
+
+func (p *TemplatePromptProvider) Prompts_Capability() *CapPrompts {
+	return &CapPrompts{}
+}
+
+func (p *TemplatePromptProvider) Prompts_OnList(cursor string) []ListPromptsResponse {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	specs := make([]PromptSpec, 0, len(p.prompts))
+	for _, prompt := range p.prompts {
+		specs = append(specs, prompt.spec)
+	}
+	return []ListPromptsResponse{{Prompts: specs}}
+}
+
+func (p *TemplatePromptProvider) Prompts_Find(name string) (PromptSpec, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	if i := p.indexOf(name); i >= 0 {
+		return p.prompts[i].spec, true
+	}
+	return PromptSpec{}, false
+}
+
+func (p *TemplatePromptProvider) Prompts_OnGet(name string, args map[string]string) (PromptGetResponse, *jsonrpc2.ErrorObject) {
+	p.mutex.RLock()
+	i := p.indexOf(name)
+	var prompt *TemplatePrompt
+	if i >= 0 {
+		prompt = p.prompts[i]
+	}
+	p.mutex.RUnlock()
+	if prompt == nil {
+		return PromptGetResponse{}, errObj(jsonrpc2.ErrObjInvalidParams)
+	}
+	return prompt.Render(args)
+}
+
+func (p *TemplatePromptProvider) Prompts_ListChanged() chan struct{} {
+	return nil
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+func TestTemplatePrompts(t *testing.T) {
+	review, err := NewPromptBuilder("code_review", "Review a change").
+		Argument("language", "Programming language", true).
+		Argument("focus", "What to focus on", false).
+		Message("user", "Review this {{.language}} change.{{with .focus}} Focus on {{.}}.{{end}}").
+		Message("assistant", "Sure, send the {{.language}} diff.").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, err := NewPromptBuilder("broken", "").Message("user", "{{.oops").Build(); err == nil {
+		t.Fatal("Expected error for invalid template")
+	}
+
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapPromptsProvider:   NewTemplatePromptProvider(review),
+	}
+	clientProvider := &testClientImpl{roots_ListChanged: make(chan struct{})}
+	clientInstance := &ClientImpl{
+		CapRootsProvider:    clientProvider,
+		CapSamplingProvider: clientProvider,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		pages, err := ts.Client.PromptsList(ts.Ctx, "")
+		if err != nil {
+			t.Fatalf("PromptsList failed: %v", err)
+		}
+		if len(pages) != 1 || len(pages[0].Prompts) != 1 || len(pages[0].Prompts[0].Arguments) != 2 {
+			t.Errorf("Unexpected prompts: %v", pages)
+		}
+	})
+
+	t.Run("Render", func(t *testing.T) {
+		response, err := ts.Client.PromptsGet(ts.Ctx, "code_review", map[string]string{"language": "Go", "focus": "errors"})
+		if err != nil {
+			t.Fatalf("PromptsGet failed: %v", err)
+		}
+		if len(response.Messages) != 2 ||
+			response.Messages[0].Content.Text != "Review this Go change. Focus on errors." ||
+			response.Messages[1].Role != "assistant" || response.Messages[1].Content.Text != "Sure, send the Go diff." {
+			t.Errorf("Unexpected messages: %+v", response.Messages)
+		}
+
+		response, err = ts.Client.PromptsGet(ts.Ctx, "code_review", map[string]string{"language": "Go"})
+		if err != nil || response.Messages[0].Content.Text != "Review this Go change." {
+			t.Errorf("Unexpected response without optional argument: %+v, %v", response, err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, name := range []string{"code_review", "unknown"} {
+			_, err := ts.Client.PromptsGet(ts.Ctx, name, map[string]string{"focus": "errors"})
+			if rpcErr, ok := err.(*jsonrpc2.ErrorObject); !ok || rpcErr.Code != jsonrpc2.JSONRPC2ErrorInvalidParams {
+				t.Errorf("Expected invalid params for %q, got %v", name, err)
+			}
+		}
+	})
+}
@@ -3,6 +3,8 @@ package mcp
 import (
 	"testing"
 	"time"
+
+	"github.com/vibeus/mcp/jsonrpc2"
 )
 
 func TestPromptsCapability(t *testing.T) {
@@ -42,17 +44,45 @@ func TestPromptsCapability(t *testing.T) {
 	})
 
 	t.Run("GetPrompt", func(t *testing.T) {
-		presp, err := ts.Client.PromptsGet(ts.Ctx, "test_prompt")
+		presp, err := ts.Client.PromptsGet(ts.Ctx, "test_prompt", map[string]string{"question": "why?"})
 		if err != nil {
 			t.Fatalf("PromptsGet failed: %v", err)
 		}
-		if len(presp.Messages) == 0 || len(presp.Messages[0].Content.Text) == 0 {
+		if len(presp.Messages) == 0 || presp.Messages[0].Content.Text != "Question to ask: why?" {
 			t.Fatal("Expected at least one test message in the prompt")
 		}
 	})
 
+	t.Run("GetPromptMissingArgument", func(t *testing.T) {
+		_, err := ts.Client.PromptsGet(ts.Ctx, "test_prompt", nil)
+		rpcErr, ok := err.(*jsonrpc2.ErrorObject)
+		if !ok {
+			t.Fatalf("Expected jsonrpc2.ErrorObject, got %T", err)
+		}
+		if rpcErr.Code != jsonrpc2.JSONRPC2ErrorInvalidParams || rpcErr.Data == nil || string(*rpcErr.Data) != `{"missing":["question"]}` {
+			t.Errorf("Unexpected error: %v", rpcErr)
+		}
+	})
+
+	t.Run("GetPromptMissingArgumentListed", func(t *testing.T) {
+		// hides Prompts_Find, for the prompt to be looked up in the list
+		listed, err := SetupClientServer(&ServerImpl{
+			MCPVersionNegotiator: serverProvider,
+			CapPromptsProvider:   struct{ CapPromptsProvider }{serverProvider},
+		}, &ClientImpl{})
+		if err != nil {
+			t.Fatalf("Failed to setup test: %v", err)
+		}
+		defer listed.Cleanup()
+		listed.Init(t)
+		_, err = listed.Client.PromptsGet(listed.Ctx, "test_prompt", nil)
+		if rpcErr, ok := err.(*jsonrpc2.ErrorObject); !ok || rpcErr.Code != jsonrpc2.JSONRPC2ErrorInvalidParams {
+			t.Errorf("Expected invalid params, got %v", err)
+		}
+	})
+
 	t.Run("GetNonexistentPrompt", func(t *testing.T) {
-		_, err := ts.Client.PromptsGet(ts.Ctx, "bad_prompt")
+		_, err := ts.Client.PromptsGet(ts.Ctx, "bad_prompt", nil)
 		if err == nil {
 			t.Fatal("Expected error for non-existent prompt")
 		}
@@ -0,0 +1,492 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+	"github.com/vibeus/mcp/uritemplate"
+)
+
+// DefaultNamespaceSeparator separates the namespace of an upstream from the
+// names of its tools, prompts and resources.
+const DefaultNamespaceSeparator = "__"
+
+// DefaultProxyListTimeout bounds the listing of the catalog of each upstream
+// of a [Proxy].
+var DefaultProxyListTimeout time.Duration = 5 * time.Second
+
+// Upstream is a server aggregated by a [Proxy], through an initialized client.
+type Upstream struct {
+	// Namespace prefixes the names of the tools, prompts and resources of the
+	// upstream. Names are kept as is if empty.
+	Namespace string
+	Client    *ClientState
+}
+
+// UpstreamHealth is the health of an upstream as last seen by a [Proxy].
+// Upstreams are unhealthy once a request to them fails for another reason
+// than an error answered by the upstream, until a request succeeds again.
+type UpstreamHealth struct {
+	Namespace string
+	Healthy   bool
+	// error of the failed request, if unhealthy
+	Err error
+	// time of the last change of health
+	Since time.Time
+}
+
+// Proxy is a server aggregating the tools, prompts and resources of several
+// upstream servers. Tools and prompts are named after the namespace of their
+// upstream, and their calls routed back to it. Resources keep their URIs, and
+// reads are routed to the upstream listing them. The list_changed
+// notifications of upstreams are passed through.
+//
+// Like [ServerImpl], a Proxy serves a single session. Upstreams may be shared
+// by the proxies of several sessions.
+type Proxy struct {
+	ServerImpl
+	// Separator joins namespaces and names, defaults to
+	// DefaultNamespaceSeparator.
+	Separator string
+	// ListTimeout bounds the listing of each upstream, DefaultProxyListTimeout
+	// if zero. Upstreams are listed concurrently, and those failing to answer
+	// in time are left out of the lists.
+	ListTimeout time.Duration
+
+	upstreams []Upstream
+	// unsubscribe removes the callbacks registered on the upstreams
+	unsubscribe []func()
+
+	mutex     sync.Mutex
+	health    []UpstreamHealth
+	resources map[string]int // URI of listed resources -> upstream
+	templates []proxyTemplate
+
+	promptsStarted   sync.Once
+	toolsStarted     sync.Once
+	resourcesStarted sync.Once
+}
+
+type proxyTemplate struct {
+	template *uritemplate.Template
+	upstream int
+}
+
+// NewProxy returns a proxy of upstreams, whose clients must be initialized.
+func NewProxy(upstreams ...Upstream) *Proxy {
+	p := &Proxy{
+		Separator: DefaultNamespaceSeparator,
+		upstreams: upstreams,
+		resources: make(map[string]int),
+	}
+	p.MCPVersionNegotiator = p
+	p.CapPromptsProvider = p
+	p.CapToolsProvider = p
+	p.CapResourcesProvider = p
+
+	now := time.Now()
+	for _, upstream := range upstreams {
+		p.health = append(p.health, UpstreamHealth{Namespace: upstream.Namespace, Healthy: true, Since: now})
+		p.unsubscribe = append(p.unsubscribe,
+			upstream.Client.OnToolsListChanged(func() {
+				p.notify(func(s *ServerState) error { return s.NotifyToolsListChanged(s.ctx) })
+			}),
+			upstream.Client.OnPromptsListChanged(func() {
+				p.notify(func(s *ServerState) error { return s.NotifyPromptsListChanged(s.ctx) })
+			}),
+			upstream.Client.OnResourcesListChanged(func() {
+				p.notify(func(s *ServerState) error { return s.NotifyResourcesListChanged(s.ctx) })
+			}),
+		)
+	}
+	return p
+}
+
+// BindState binds the proxy to the session of server. The notifications of
+// the upstreams stop being passed through once the session ends.
+func (p *Proxy) BindState(server *ServerState) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.ServerImpl.BindState(server)
+	go func() {
+		<-server.Done()
+		for _, unsubscribe := range p.unsubscribe {
+			unsubscribe()
+		}
+	}()
+}
+
+func (p *Proxy) NegotiateMCPVersion(clientVersion string) string {
+	return LatestMCPVersion
+}
+
+// Health returns the health of the upstreams, in order.
+func (p *Proxy) Health() []UpstreamHealth {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]UpstreamHealth(nil), p.health...)
+}
+
+// CheckHealth pings the upstreams and returns their health.
+func (p *Proxy) CheckHealth(ctx context.Context) []UpstreamHealth {
+	var wg sync.WaitGroup
+	for i, upstream := range p.upstreams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.report(i, upstream.Client.Ping(ctx))
+		}()
+	}
+	wg.Wait()
+	return p.Health()
+}
+
+// report updates the health of upstream i after a request that returned err.
+// Errors answered by the upstream leave it healthy.
+func (p *Proxy) report(i int, err error) {
+	var errv jsonrpc2.ErrorObject
+	var erro *jsonrpc2.ErrorObject
+	if errors.As(err, &errv) || errors.As(err, &erro) {
+		err = nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	health := &p.health[i]
+	if health.Healthy == (err == nil) {
+		health.Err = err
+		return
+	}
+	health.Healthy, health.Err, health.Since = err == nil, err, time.Now()
+	if p.server == nil {
+		return
+	}
+	if logger := p.server.ctx.GetSession().GetLogger(); logger != nil {
+		if err != nil {
+			logger.Warn("upstream unhealthy", "namespace", health.Namespace, "error", err)
+		} else {
+			logger.Info("upstream healthy", "namespace", health.Namespace)
+		}
+	}
+}
+
+// notify sends a notification to the session of p, once initialized.
+func (p *Proxy) notify(send func(*ServerState) error) {
+	p.mutex.Lock()
+	server := p.server
+	p.mutex.Unlock()
+	if server == nil || server.ctx.GetSession().GetMCPState() != MCPState_Initialized {
+		return
+	}
+	send(server)
+}
+
+// ctx returns the context of the requests to upstreams.
+func (p *Proxy) ctx() context.Context {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.server == nil {
+		return context.Background()
+	}
+	return p.server.ctx
+}
+
+// capabilities returns the capabilities of upstream i.
+func (p *Proxy) capabilities(i int) *ServerCapabilities {
+	return p.upstreams[i].Client.ctx.GetSession().GetServerCapabilities()
+}
+
+// qualify returns name in the namespace of upstream i.
+func (p *Proxy) qualify(i int, name string) string {
+	if p.upstreams[i].Namespace == "" {
+		return name
+	}
+	return p.upstreams[i].Namespace + p.Separator + name
+}
+
+// route returns the upstream of the tool or prompt named name, and its name
+// upstream. The longest matching namespace wins, so that "a__b__x" goes to
+// namespace "a__b" rather than "a". Names without a known namespace go to the
+// first upstream without namespace.
+func (p *Proxy) route(name string) (int, string, bool) {
+	best, unqualified := -1, ""
+	for i, upstream := range p.upstreams {
+		if upstream.Namespace == "" || (best >= 0 && len(upstream.Namespace) <= len(p.upstreams[best].Namespace)) {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(name, upstream.Namespace+p.Separator); ok {
+			best, unqualified = i, rest
+		}
+	}
+	if best >= 0 {
+		return best, unqualified, true
+	}
+	for i, upstream := range p.upstreams {
+		if upstream.Namespace == "" {
+			return i, name, true
+		}
+	}
+	return 0, "", false
+}
+
+// live reports whether the connection to upstream i is still open, reporting
+// it unhealthy otherwise.
+func (p *Proxy) live(i int) bool {
+	select {
+	case <-p.upstreams[i].Client.Done():
+		p.report(i, ErrConnectionLost)
+		return false
+	default:
+		return true
+	}
+}
+
+// listUpstreams lists the items of the live upstreams having the capability
+// told by has, concurrently and each within the list timeout. It returns the
+// items by upstream, nil for the upstreams skipped or failing to list them.
+func listUpstreams[T any](p *Proxy, has func(*ServerCapabilities) bool, all func(*ClientState, context.Context, ...PageOption) iter.Seq2[T, error]) [][]T {
+	timeout := p.ListTimeout
+	if timeout <= 0 {
+		timeout = DefaultProxyListTimeout
+	}
+	ctx := p.ctx()
+	listed := make([][]T, len(p.upstreams))
+	var wg sync.WaitGroup
+	for i, upstream := range p.upstreams {
+		if caps := p.capabilities(i); caps == nil || !has(caps) || !p.live(i) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			items := []T{}
+			for item, err := range all(upstream.Client, ctx) {
+				if err != nil {
+					p.report(i, err)
+					return
+				}
+				items = append(items, item)
+			}
+			p.report(i, nil)
+			listed[i] = items
+		}()
+	}
+	wg.Wait()
+	return listed
+}
+
+// anyCapability reports whether some upstream has the capability told by has.
+func (p *Proxy) anyCapability(has func(*ServerCapabilities) bool) bool {
+	for i := range p.upstreams {
+		if caps := p.capabilities(i); caps != nil && has(caps) {
+			return true
+		}
+	}
+	return false
+}
+
+// CapToolsProvider implementation
+func (p *Proxy) Tools_Started() *sync.Once {
+	return &p.toolsStarted
+}
+func (p *Proxy) Tools_Capability() *CapTools {
+	if !p.anyCapability(func(caps *ServerCapabilities) bool { return caps.Tools != nil }) {
+		return nil
+	}
+	return &CapTools{ListChanged: true}
+}
+func (p *Proxy) Tools_ListChanged() chan struct{} {
+	return nil
+}
+
+// Tools_OnList lists the tools of all upstreams in a single page. The tools of
+// upstreams failing to list them are left out.
+func (p *Proxy) Tools_OnList(cursor string) []ListToolsResonponse {
+	tools := []ToolSpec{}
+	listed := listUpstreams(p, func(caps *ServerCapabilities) bool { return caps.Tools != nil }, (*ClientState).AllTools)
+	for i, items := range listed {
+		for _, tool := range items {
+			tool.Name = p.qualify(i, tool.Name)
+			tools = append(tools, tool)
+		}
+	}
+	return []ListToolsResonponse{{Tools: tools}}
+}
+
+func (p *Proxy) Tools_OnCall(name string, args map[string]string) (ToolCallResponse, *jsonrpc2.ErrorObject) {
+	i, unqualified, ok := p.route(name)
+	if !ok {
+		return ToolCallResponse{}, errObj(jsonrpc2.ErrObjInvalidParams)
+	}
+	res, err := p.upstreams[i].Client.ToolCall(p.ctx(), unqualified, args)
+	p.report(i, err)
+	if err != nil {
+		return ToolCallResponse{}, errObj(toErrorObject(err, p.upstreams[i].Client.logger()))
+	}
+	return res, nil
+}
+
+// CapPromptsProvider implementation
+func (p *Proxy) Prompts_Started() *sync.Once {
+	return &p.promptsStarted
+}
+func (p *Proxy) Prompts_Capability() *CapPrompts {
+	if !p.anyCapability(func(caps *ServerCapabilities) bool { return caps.Prompts != nil }) {
+		return nil
+	}
+	return &CapPrompts{ListChanged: true}
+}
+func (p *Proxy) Prompts_ListChanged() chan struct{} {
+	return nil
+}
+
+// Prompts_OnList lists the prompts of all upstreams in a single page. The
+// prompts of upstreams failing to list them are left out.
+func (p *Proxy) Prompts_OnList(cursor string) []ListPromptsResponse {
+	prompts := []PromptSpec{}
+	listed := listUpstreams(p, func(caps *ServerCapabilities) bool { return caps.Prompts != nil }, (*ClientState).AllPrompts)
+	for i, items := range listed {
+		for _, prompt := range items {
+			prompt.Name = p.qualify(i, prompt.Name)
+			prompts = append(prompts, prompt)
+		}
+	}
+	return []ListPromptsResponse{{Prompts: prompts}}
+}
+
+func (p *Proxy) Prompts_OnGet(name string, args map[string]string) (PromptGetResponse, *jsonrpc2.ErrorObject) {
+	i, unqualified, ok := p.route(name)
+	if !ok {
+		return PromptGetResponse{}, errObj(jsonrpc2.ErrObjInvalidParams)
+	}
+	res, err := p.upstreams[i].Client.PromptsGet(p.ctx(), unqualified, args)
+	p.report(i, err)
+	if err != nil {
+		return PromptGetResponse{}, errObj(toErrorObject(err, p.upstreams[i].Client.logger()))
+	}
+	return res, nil
+}
+
+// CapResourcesProvider implementation
+func (p *Proxy) Resources_Started() *sync.Once {
+	return &p.resourcesStarted
+}
+func (p *Proxy) Resources_Capability() *CapResources {
+	if !p.anyCapability(func(caps *ServerCapabilities) bool { return caps.Resources != nil }) {
+		return nil
+	}
+	return &CapResources{ListChanged: true}
+}
+func (p *Proxy) Resources_ListChanged() chan struct{} {
+	return nil
+}
+
+// Resources_OnList lists the resources of all upstreams, remembering which
+// upstream lists which URI to route reads.
+func (p *Proxy) Resources_OnList(cursor string) []ResourceSpec {
+	resources := []ResourceSpec{}
+	owners := make(map[string]int)
+	listed := listUpstreams(p, func(caps *ServerCapabilities) bool { return caps.Resources != nil }, (*ClientState).AllResources)
+	for i, items := range listed {
+		for _, resource := range items {
+			if _, ok := owners[resource.URI]; !ok {
+				owners[resource.URI] = i
+			}
+			resource.Name = p.qualify(i, resource.Name)
+			resources = append(resources, resource)
+		}
+	}
+
+	p.mutex.Lock()
+	p.resources = owners
+	p.mutex.Unlock()
+	return resources
+}
+
+// Resources_OnTemplatesList lists the resource templates of all upstreams,
+// remembering which upstream lists which template to route reads.
+func (p *Proxy) Resources_OnTemplatesList() []ResourceTemplateSpec {
+	templates := []ResourceTemplateSpec{}
+	var routes []proxyTemplate
+	listed := listUpstreams(p, func(caps *ServerCapabilities) bool { return caps.Resources != nil }, (*ClientState).AllResourceTemplates)
+	for i, items := range listed {
+		for _, template := range items {
+			if parsed, err := uritemplate.Parse(template.URITemplate); err == nil {
+				routes = append(routes, proxyTemplate{template: parsed, upstream: i})
+			}
+			template.Name = p.qualify(i, template.Name)
+			templates = append(templates, template)
+		}
+	}
+
+	p.mutex.Lock()
+	p.templates = routes
+	p.mutex.Unlock()
+	return templates
+}
+
+// Resources_OnRead reads uri like Resources_Read, without its error.
+func (p *Proxy) Resources_OnRead(uri string) []ResourceContentUnion {
+	content, _ := p.Resources_Read(uri)
+	return content
+}
+
+// Resources_Read reads uri from the upstream listing it, or the first upstream
+// listing a template matching it, and answers the errors of the upstream. URIs
+// of unknown resources are read from every live upstream in turn until one has
+// it, and are not found if none has it and none failed.
+func (p *Proxy) Resources_Read(uri string) ([]ResourceContentUnion, *jsonrpc2.ErrorObject) {
+	ctx := p.ctx()
+	read := func(i int) ([]ResourceContentUnion, error) {
+		content, err := p.upstreams[i].Client.ResourcesRead(ctx, uri)
+		p.report(i, err)
+		return content, err
+	}
+
+	p.mutex.Lock()
+	owner, ok := p.resources[uri]
+	if !ok {
+		for _, route := range p.templates {
+			if _, matched := route.template.Match(uri); matched {
+				owner, ok = route.upstream, true
+				break
+			}
+		}
+	}
+	p.mutex.Unlock()
+	if ok {
+		content, err := read(owner)
+		if err != nil {
+			return nil, errObj(toErrorObject(err, p.upstreams[owner].Client.logger()))
+		}
+		return content, nil
+	}
+
+	var failed *jsonrpc2.ErrorObject
+	for i := range p.upstreams {
+		if caps := p.capabilities(i); caps == nil || caps.Resources == nil || !p.live(i) {
+			continue
+		}
+		content, err := read(i)
+		if err == nil && len(content) > 0 {
+			return content, nil
+		}
+		// upstreams not having the resource answer an error object
+		var errv jsonrpc2.ErrorObject
+		var erro *jsonrpc2.ErrorObject
+		if err != nil && !errors.As(err, &errv) && !errors.As(err, &erro) {
+			failed = errObj(toErrorObject(err, p.upstreams[i].Client.logger()))
+		}
+	}
+	if failed != nil {
+		return nil, failed
+	}
+	return nil, errResourceNotFound(uri)
+}
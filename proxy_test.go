@@ -0,0 +1,264 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+func TestProxy(t *testing.T) {
+	// Upstream "weather" serves the test prompts, tools and resources.
+	weatherProvider := NewTestServerImpl()
+	weather, err := SetupClientServer(&ServerImpl{
+		MCPVersionNegotiator: weatherProvider,
+		CapPromptsProvider:   weatherProvider,
+		CapToolsProvider:     weatherProvider,
+		CapResourcesProvider: weatherProvider,
+	}, &ClientImpl{})
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer weather.Cleanup()
+	weather.Init(t)
+
+	// Upstream "search" serves the test tools, answering calls itself, and
+	// templated resources.
+	searchProvider := NewTestServerImpl()
+	router := NewResourceRouter()
+	err = router.Handle(ResourceTemplateSpec{URITemplate: "search://results/{query}", Name: "results"},
+		func(uri string, vars map[string]string) []ResourceContentUnion {
+			return []ResourceContentUnion{{URI: uri, Text: "Results for " + vars["query"]}}
+		})
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	searchServer := &ServerImpl{
+		MCPVersionNegotiator: searchProvider,
+		CapToolsProvider:     searchProvider,
+		CapResourcesProvider: router,
+	}
+	searchServer.Use(func(ctx context.Context, inv *Invocation, next MethodHandler) (any, error) {
+		if inv.Method == kMethodToolsCall {
+			return ToolCallResponse{Content: []Content{TextContent("Search executed")}}, nil
+		}
+		return next(ctx, inv)
+	})
+	search, err := SetupClientServer(searchServer, &ClientImpl{})
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer search.Cleanup()
+	search.Init(t)
+
+	proxy := NewProxy(
+		Upstream{Namespace: "weather", Client: weather.Client},
+		Upstream{Namespace: "search", Client: search.Client},
+	)
+	ts, err := SetupClientServer(proxy, &ClientImpl{})
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("Tools", func(t *testing.T) {
+		var names []string
+		for tool, err := range ts.Client.AllTools(ts.Ctx) {
+			if err != nil {
+				t.Fatalf("AllTools failed: %v", err)
+			}
+			names = append(names, tool.Name)
+		}
+		if len(names) != 2 || names[0] != "weather__test_tool" || names[1] != "search__test_tool" {
+			t.Errorf("Unexpected tools: %v", names)
+		}
+
+		for name, want := range map[string]string{
+			"weather__test_tool": "Tool executed successfully",
+			"search__test_tool":  "Search executed",
+		} {
+			response, err := ts.Client.ToolCall(ts.Ctx, name, map[string]string{"param1": "x"})
+			if err != nil || response.Content[0].Text != want {
+				t.Errorf("Unexpected response of %s: %+v, %v", name, response, err)
+			}
+		}
+
+		_, err := ts.Client.ToolCall(ts.Ctx, "test_tool", nil)
+		if rpcErr, ok := err.(*jsonrpc2.ErrorObject); !ok || rpcErr.Code != jsonrpc2.JSONRPC2ErrorInvalidParams {
+			t.Errorf("Expected invalid params for a tool without namespace, got %v", err)
+		}
+		_, err = ts.Client.ToolCall(ts.Ctx, "weather__unknown", nil)
+		if rpcErr, ok := err.(*jsonrpc2.ErrorObject); !ok || rpcErr.Message != "Method not found" {
+			t.Errorf("Expected the error of the upstream, got %v", err)
+		}
+	})
+
+	t.Run("Prompts", func(t *testing.T) {
+		pages, err := ts.Client.PromptsList(ts.Ctx, "")
+		if err != nil || len(pages) != 1 || len(pages[0].Prompts) != 1 || pages[0].Prompts[0].Name != "weather__test_prompt" {
+			t.Fatalf("Unexpected prompts: %+v, %v", pages, err)
+		}
+		response, err := ts.Client.PromptsGet(ts.Ctx, "weather__test_prompt", map[string]string{"question": "Rain?"})
+		if err != nil || response.Messages[0].Content.Text != "Question to ask: Rain?" {
+			t.Errorf("Unexpected prompt: %+v, %v", response, err)
+		}
+	})
+
+	t.Run("Resources", func(t *testing.T) {
+		resources, err := ts.Client.ResourcesList(ts.Ctx, "")
+		if err != nil || len(resources.Resources) != 1 || resources.Resources[0].Name != "weather__Test Resource" {
+			t.Fatalf("Unexpected resources: %+v, %v", resources, err)
+		}
+		templates, err := ts.Client.ResourcesTemplatesList(ts.Ctx, "")
+		if err != nil || len(templates.ResourceTemplates) != 2 || templates.ResourceTemplates[1].Name != "search__results" {
+			t.Fatalf("Unexpected templates: %+v, %v", templates, err)
+		}
+
+		content, err := ts.Client.ResourcesRead(ts.Ctx, "search://results/go")
+		if err != nil || content[0].Text != "Results for go" {
+			t.Errorf("Unexpected content: %+v, %v", content, err)
+		}
+		content, err = ts.Client.ResourcesRead(ts.Ctx, "resource://test/0")
+		if err != nil || content[0].Text != "Test resource content" {
+			t.Errorf("Unexpected content: %+v, %v", content, err)
+		}
+		if _, err := ts.Client.ResourcesRead(ts.Ctx, "resource://missing"); err == nil {
+			t.Error("Expected missing resource to fail")
+		}
+	})
+
+	t.Run("ListChanged", func(t *testing.T) {
+		received := make(chan string, 2)
+		ts.Client.OnToolsListChanged(func() { received <- kMethodToolsListChanged })
+		ts.Client.OnPromptsListChanged(func() { received <- kMethodPromptsListChanged })
+		weatherProvider.tools_ListChanged <- struct{}{}
+		weatherProvider.prompts_ListChanged <- struct{}{}
+		// The upstream notifies of each list independently, in any order.
+		got := make(map[string]bool)
+		for range 2 {
+			select {
+			case method := <-received:
+				got[method] = true
+			case <-time.After(time.Second):
+				t.Fatalf("Timeout waiting for notifications, got %v", got)
+			}
+		}
+		if !got[kMethodToolsListChanged] || !got[kMethodPromptsListChanged] {
+			t.Errorf("Unexpected notifications: %v", got)
+		}
+	})
+
+	t.Run("SessionEnd", func(t *testing.T) {
+		callbacks := func() int {
+			weather.Client.notifications.mutex.Lock()
+			defer weather.Client.notifications.mutex.Unlock()
+			return len(weather.Client.notifications.callbacks.toolsListChanged)
+		}
+		before := callbacks()
+		session, err := SetupClientServer(NewProxy(Upstream{Namespace: "weather", Client: weather.Client}), &ClientImpl{})
+		if err != nil {
+			t.Fatalf("Failed to setup test: %v", err)
+		}
+		session.Init(t)
+		if got := callbacks(); got != before+1 {
+			t.Errorf("Expected the proxy to register a callback, got %d callbacks for %d", got, before)
+		}
+		session.Cleanup()
+		deadline := time.Now().Add(time.Second)
+		for callbacks() != before && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got := callbacks(); got != before {
+			t.Errorf("Expected the callback removed with the session, got %d callbacks for %d", got, before)
+		}
+	})
+
+	t.Run("Route", func(t *testing.T) {
+		nested := NewProxy(
+			Upstream{Namespace: "a", Client: weather.Client},
+			Upstream{Namespace: "a__b", Client: search.Client},
+		)
+		for name, want := range map[string]struct {
+			upstream int
+			name     string
+		}{
+			"a__tool":      {0, "tool"},
+			"a__b__tool":   {1, "tool"},
+			"a__bc__tool":  {0, "bc__tool"},
+			"a__b__c__x":   {1, "c__x"},
+			"a__b_tool":    {0, "b_tool"},
+			"a__b____tool": {1, "__tool"},
+		} {
+			i, unqualified, ok := nested.route(name)
+			if !ok || i != want.upstream || unqualified != want.name {
+				t.Errorf("route(%q) = %d, %q, %v, want %d, %q", name, i, unqualified, ok, want.upstream, want.name)
+			}
+		}
+	})
+
+	t.Run("ListTimeout", func(t *testing.T) {
+		block := make(chan struct{})
+		slowProvider := NewTestServerImpl()
+		slowServer := &ServerImpl{MCPVersionNegotiator: slowProvider, CapToolsProvider: slowProvider}
+		slowServer.Use(func(ctx context.Context, inv *Invocation, next MethodHandler) (any, error) {
+			if inv.Method == kMethodToolsList {
+				<-block
+			}
+			return next(ctx, inv)
+		})
+		slow, err := SetupClientServer(slowServer, &ClientImpl{})
+		if err != nil {
+			t.Fatalf("Failed to setup test: %v", err)
+		}
+		defer slow.Cleanup()
+		defer close(block)
+		slow.Init(t)
+
+		p := NewProxy(
+			Upstream{Namespace: "slow", Client: slow.Client},
+			Upstream{Namespace: "weather", Client: weather.Client},
+		)
+		p.ListTimeout = 100 * time.Millisecond
+		start := time.Now()
+		pages := p.Tools_OnList("")
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Listing took %v", elapsed)
+		}
+		if len(pages[0].Tools) != 1 || pages[0].Tools[0].Name != "weather__test_tool" {
+			t.Errorf("Expected the tools of weather only, got %+v", pages)
+		}
+		if health := p.Health(); health[0].Healthy || !health[1].Healthy {
+			t.Errorf("Expected slow to be unhealthy, got %+v", health)
+		}
+	})
+
+	t.Run("Health", func(t *testing.T) {
+		health := proxy.CheckHealth(ts.Ctx)
+		if len(health) != 2 || !health[0].Healthy || !health[1].Healthy {
+			t.Fatalf("Expected healthy upstreams, got %+v", health)
+		}
+
+		search.Cleanup()
+		ctx, cancel := context.WithTimeout(ts.Ctx, 500*time.Millisecond)
+		defer cancel()
+		health = proxy.CheckHealth(ctx)
+		if !health[0].Healthy || health[1].Healthy || health[1].Namespace != "search" || health[1].Err == nil {
+			t.Errorf("Expected search to be unhealthy, got %+v", health)
+		}
+
+		pages, err := ts.Client.ToolsList(ts.Ctx, "")
+		if err != nil || len(pages[0].Tools) != 1 || pages[0].Tools[0].Name != "weather__test_tool" {
+			t.Errorf("Expected the tools of healthy upstreams only, got %+v, %v", pages, err)
+		}
+
+		_, err = ts.Client.ResourcesRead(ts.Ctx, "search://results/go")
+		if rpcErr, ok := err.(*jsonrpc2.ErrorObject); !ok || rpcErr.Code != jsonrpc2.JSONRPC2ErrorInternalError {
+			t.Errorf("Expected the read failure to be answered, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"sync"
+
+	"github.com/vibeus/mcp/uritemplate"
+)
+
+// ResourceHandler reads the resource at uri, given the variables extracted
+// from it by the template it was routed by. It returns no content if the
+// resource does not exist.
+type ResourceHandler func(uri string, vars map[string]string) []ResourceContentUnion
+
+// ResourceRouter is a [CapResourcesProvider] dispatching resources/read
+// requests to handlers registered per RFC 6570 URI template.
+//
+// Templates with variables are listed by resources/templates/list, and
+// templates without variables are listed by resources/list as plain
+// resources.
+type ResourceRouter struct {
+	started sync.Once
+
+	mutex  sync.RWMutex
+	routes []resourceRoute
+}
+
+type resourceRoute struct {
+	spec     ResourceTemplateSpec
+	template *uritemplate.Template
+	handler  ResourceHandler
+}
+
+func NewResourceRouter() *ResourceRouter {
+	return &ResourceRouter{}
+}
+
+// Handle registers handler for the URIs matching spec.URITemplate. When several
+// templates match a URI, the first registered one wins.
+func (r *ResourceRouter) Handle(spec ResourceTemplateSpec, handler ResourceHandler) error {
+	template, err := uritemplate.Parse(spec.URITemplate)
+	if err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.routes = append(r.routes, resourceRoute{spec: spec, template: template, handler: handler})
+	return nil
+}
+
+func (r *ResourceRouter) Resources_Started() *sync.Once {
+	return &r.started
+}
+
+func (r *ResourceRouter) Resources_Capability() *CapResources {
+	return &CapResources{}
+}
+
+func (r *ResourceRouter) Resources_OnList(cursor string) []ResourceSpec {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var resources []ResourceSpec
+	for _, route := range r.routes {
+		if len(route.template.Varnames()) == 0 {
+			resources = append(resources, ResourceSpec{
+				URI:         route.spec.URITemplate,
+				Name:        route.spec.Name,
+				Title:       route.spec.Title,
+				Description: route.spec.Description,
+				MimeType:    route.spec.MimeType,
+				Meta:        route.spec.Meta,
+			})
+		}
+	}
+	return resources
+}
+
+func (r *ResourceRouter) Resources_OnTemplatesList() []ResourceTemplateSpec {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var templates []ResourceTemplateSpec
+	for _, route := range r.routes {
+		if len(route.template.Varnames()) > 0 {
+			templates = append(templates, route.spec)
+		}
+	}
+	return templates
+}
+
+func (r *ResourceRouter) Resources_OnRead(uri string) []ResourceContentUnion {
+	r.mutex.RLock()
+	routes := r.routes
+	r.mutex.RUnlock()
+	for _, route := range routes {
+		if vars, ok := route.template.Match(uri); ok {
+			return route.handler(uri, vars)
+		}
+	}
+	return nil
+}
+
+func (r *ResourceRouter) Resources_ListChanged() chan struct{} {
+	return nil
+}
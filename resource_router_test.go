@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"testing"
+)
+
+func TestResourceRouter(t *testing.T) {
+	router := NewResourceRouter()
+	err := router.Handle(ResourceTemplateSpec{URITemplate: "docs://readme", Name: "Readme"},
+		func(uri string, vars map[string]string) []ResourceContentUnion {
+			return []ResourceContentUnion{{URI: uri, MimeType: "text/plain", Text: "read me"}}
+		})
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	err = router.Handle(ResourceTemplateSpec{URITemplate: "users://{id}/posts{/post}", Name: "Posts"},
+		func(uri string, vars map[string]string) []ResourceContentUnion {
+			if vars["id"] != "42" {
+				return nil
+			}
+			return []ResourceContentUnion{{URI: uri, Text: "user " + vars["id"] + " post " + vars["post"]}}
+		})
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if err := router.Handle(ResourceTemplateSpec{URITemplate: "bad://{id"}, nil); err == nil {
+		t.Fatal("Expected error for invalid template")
+	}
+
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapResourcesProvider: router,
+	}
+	clientProvider := &testClientImpl{roots_ListChanged: make(chan struct{})}
+	clientInstance := &ClientImpl{
+		CapRootsProvider:    clientProvider,
+		CapSamplingProvider: clientProvider,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		resources, err := ts.Client.ResourcesList(ts.Ctx, "")
+		if err != nil {
+			t.Fatalf("ResourcesList failed: %v", err)
+		}
+		if len(resources.Resources) != 1 || resources.Resources[0].URI != "docs://readme" {
+			t.Errorf("Unexpected resources: %v", resources.Resources)
+		}
+		templates, err := ts.Client.ResourcesTemplatesList(ts.Ctx, "")
+		if err != nil {
+			t.Fatalf("ResourcesTemplatesList failed: %v", err)
+		}
+		if len(templates.ResourceTemplates) != 1 || templates.ResourceTemplates[0].Name != "Posts" {
+			t.Errorf("Unexpected templates: %v", templates.ResourceTemplates)
+		}
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		content, err := ts.Client.ResourcesRead(ts.Ctx, "docs://readme")
+		if err != nil || len(content) != 1 || content[0].Text != "read me" {
+			t.Errorf("Unexpected content: %v, %v", content, err)
+		}
+		content, err = ts.Client.ResourcesRead(ts.Ctx, "users://42/posts/7")
+		if err != nil || len(content) != 1 || content[0].Text != "user 42 post 7" {
+			t.Errorf("Unexpected content: %v, %v", content, err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		for _, uri := range []string{"users://1/posts/7", "users://42/comments", "other://x"} {
+			if _, err := ts.Client.ResourcesRead(ts.Ctx, uri); err == nil {
+				t.Errorf("Expected error reading %q", uri)
+			}
+		}
+	})
+}
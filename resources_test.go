@@ -45,11 +45,11 @@ func TestResourcesCapability(t *testing.T) {
 	})
 
 	t.Run("ListResourceTemplates", func(t *testing.T) {
-		templates, err := ts.Client.ResourcesTemplatesList(ts.Ctx)
+		response, err := ts.Client.ResourcesTemplatesList(ts.Ctx, "")
 		if err != nil {
-			t.Fatalf("ResourcesList failed: %v", err)
+			t.Fatalf("ResourcesTemplatesList failed: %v", err)
 		}
-		if len(templates) == 0 {
+		if len(response.ResourceTemplates) == 0 {
 			t.Error("Expected at least one test resource")
 		}
 	})
@@ -100,3 +100,78 @@ func TestResourcesCapability(t *testing.T) {
 	ts.Cancel()
 	<-time.After(100 * time.Millisecond) // Allow for graceful shutdown
 }
+
+// pagedTemplatesServerImpl serves its resource templates in pages.
+type pagedTemplatesServerImpl struct {
+	*testServerImpl
+	templates []ResourceTemplateSpec
+	paginator *Paginator
+}
+
+func (c *pagedTemplatesServerImpl) Resources_OnTemplatesListPage(cursor string) (ResourcesTemplatesListResponse, *jsonrpc2.ErrorObject) {
+	page, next, erro := Paginate(c.paginator, c.templates, cursor)
+	if erro != nil {
+		return ResourcesTemplatesListResponse{}, erro
+	}
+	return ResourcesTemplatesListResponse{ResourceTemplates: page, NextCursor: next}, nil
+}
+
+func TestResourceTemplatesPagination(t *testing.T) {
+	// Setup test environment
+	serverProvider := &pagedTemplatesServerImpl{
+		testServerImpl: NewTestServerImpl(),
+		paginator:      NewPaginator(2),
+	}
+	for _, table := range []string{"users", "orders", "items"} {
+		serverProvider.templates = append(serverProvider.templates, ResourceTemplateSpec{
+			URITemplate: "db://" + table + "/{id}",
+			Name:        table,
+		})
+	}
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapResourcesProvider: serverProvider,
+	}
+	clientProvider := &testClientImpl{roots_ListChanged: make(chan struct{})}
+	clientInstance := &ClientImpl{
+		CapRootsProvider:    clientProvider,
+		CapSamplingProvider: clientProvider,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("FirstPage", func(t *testing.T) {
+		response, err := ts.Client.ResourcesTemplatesList(ts.Ctx, "")
+		if err != nil {
+			t.Fatalf("ResourcesTemplatesList failed: %v", err)
+		}
+		if len(response.ResourceTemplates) != 2 || response.NextCursor == "" {
+			t.Errorf("Expected a page of 2 templates with a next cursor, got %v", response)
+		}
+	})
+
+	t.Run("AllPages", func(t *testing.T) {
+		templates, err := collect(ts.Client.AllResourceTemplates(ts.Ctx))
+		if err != nil {
+			t.Fatalf("AllResourceTemplates failed: %v", err)
+		}
+		if len(templates) != 3 || templates[2].Name != "items" {
+			t.Errorf("Unexpected templates: %v", templates)
+		}
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		_, err := ts.Client.ResourcesTemplatesList(ts.Ctx, "tampered")
+		if rpcErr, ok := err.(*jsonrpc2.ErrorObject); !ok || rpcErr.Code != jsonrpc2.JSONRPC2ErrorInvalidParams {
+			t.Errorf("Expected invalid params, got %v", err)
+		}
+	})
+}
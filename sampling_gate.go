@@ -0,0 +1,199 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+// DefaultSamplingRatePeriod is the period of the rate limit of a
+// [SamplingGate] without RatePeriod.
+var DefaultSamplingRatePeriod = time.Minute
+
+// SamplingGate puts a user in the loop of the sampling requests of servers,
+// before a CapSamplingProvider sends them to a model. Requests are rate limited
+// and their maxTokens bounded per policy, then shown to the user, who may edit
+// or reject them. Responses of the model are shown to the user as well before
+// being returned to the server.
+//
+// A gate is shared by the clients of several servers, each using the provider
+// returned by ForServer.
+type SamplingGate struct {
+	Provider CapSamplingProvider
+
+	// ReviewRequest shows a request of server to the user. It returns the
+	// request to send to the model, possibly edited, or false to reject it.
+	// Requests are rejected if nil. ctx is done when the session ends, and the
+	// review should then be abandoned.
+	ReviewRequest func(ctx context.Context, server string, msg SamplingMessage) (SamplingMessage, bool)
+	// ReviewResponse shows the response of the model to msg to the user. It
+	// returns the response to return to server, possibly edited, or false to
+	// reject it. Responses are returned as is if nil.
+	ReviewResponse func(ctx context.Context, server string, msg SamplingMessage, res SamplingResponse) (SamplingResponse, bool)
+
+	// MaxTokens bounds the maxTokens of requests if positive. Requests asking
+	// for more are lowered to MaxTokens, or rejected if RejectOverMaxTokens.
+	MaxTokens           int64
+	RejectOverMaxTokens bool
+
+	// RateLimit bounds the requests of each server to RateLimit per RatePeriod
+	// if positive. Requests over the limit are rejected without review.
+	// RatePeriod defaults to DefaultSamplingRatePeriod if not positive.
+	RateLimit  int
+	RatePeriod time.Duration
+
+	mutex    sync.Mutex
+	requests map[string][]time.Time // per server, within the last RatePeriod
+	now      func() time.Time
+}
+
+func NewSamplingGate(provider CapSamplingProvider) *SamplingGate {
+	return &SamplingGate{Provider: provider}
+}
+
+// ForServer returns the provider to handle the sampling requests of the server
+// named server through the gate.
+func (g *SamplingGate) ForServer(server string) CapSamplingProvider {
+	return &gatedSampler{gate: g, server: server}
+}
+
+// allow records a request of server, and reports whether it is within the
+// rate limit.
+func (g *SamplingGate) allow(server string) bool {
+	if g.RateLimit <= 0 {
+		return true
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	now := time.Now()
+	if g.now != nil {
+		now = g.now()
+	}
+	if g.requests == nil {
+		g.requests = make(map[string][]time.Time)
+	}
+	period := g.RatePeriod
+	if period <= 0 {
+		period = DefaultSamplingRatePeriod
+	}
+	recent := g.requests[server]
+	for len(recent) > 0 && now.Sub(recent[0]) >= period {
+		recent = recent[1:]
+	}
+	if len(recent) >= g.RateLimit {
+		g.requests[server] = recent
+		return false
+	}
+	g.requests[server] = append(recent, now)
+	return true
+}
+
+// limitTokens applies the maximum token policy to msg.
+func (g *SamplingGate) limitTokens(msg *SamplingMessage) *jsonrpc2.ErrorObject {
+	if g.MaxTokens <= 0 || msg.MaxTokens <= g.MaxTokens {
+		return nil
+	}
+	if g.RejectOverMaxTokens {
+		return &kErrObjSamplingTooManyTokens
+	}
+	msg.MaxTokens = g.MaxTokens
+	return nil
+}
+
+type gatedSampler struct {
+	gate   *SamplingGate
+	server string
+}
+
+func (s *gatedSampler) Sampling_Capability() *CapSampling {
+	return s.gate.Provider.Sampling_Capability()
+}
+
+// HandleRequest serves the callers not supporting [CapSamplingCreator], with
+// a context that is never done.
+func (s *gatedSampler) HandleRequest(w jsonrpc2.ResponseWriterOf[SamplingResponse], msg SamplingMessage) error {
+	res, err := s.Sampling_OnCreateMessage(context.Background(), msg)
+	if err != nil {
+		var erro *jsonrpc2.ErrorObject
+		if errors.As(err, &erro) {
+			return w.WriteError(*erro)
+		}
+		return err
+	}
+	return w.WriteResponse(res)
+}
+
+func (s *gatedSampler) Sampling_OnCreateMessage(ctx context.Context, msg SamplingMessage) (SamplingResponse, error) {
+	g := s.gate
+	if !g.allow(s.server) {
+		return SamplingResponse{}, errObj(kErrObjSamplingRateLimited)
+	}
+	if erro := g.limitTokens(&msg); erro != nil {
+		return SamplingResponse{}, erro
+	}
+	if g.ReviewRequest == nil {
+		return SamplingResponse{}, errObj(kErrObjSamplingRejected)
+	}
+	msg, ok := g.ReviewRequest(ctx, s.server, msg)
+	if !ok {
+		return SamplingResponse{}, errObj(kErrObjSamplingRejected)
+	}
+	// The user may have edited maxTokens beyond the policy.
+	if erro := g.limitTokens(&msg); erro != nil {
+		return SamplingResponse{}, erro
+	}
+	if msg.Validate() != nil {
+		return SamplingResponse{}, errObj(jsonrpc2.ErrObjInvalidParams)
+	}
+
+	res, err := createMessage(ctx, g.Provider, msg)
+	if err != nil {
+		return SamplingResponse{}, err
+	}
+	if g.ReviewResponse != nil {
+		if res, ok = g.ReviewResponse(ctx, s.server, msg, res); !ok {
+			return SamplingResponse{}, errObj(kErrObjSamplingRejected)
+		}
+	}
+	return res, nil
+}
+
+// createMessage asks provider to create a message for msg, with ctx if it is a
+// [CapSamplingCreator].
+func createMessage(ctx context.Context, provider CapSamplingProvider, msg SamplingMessage) (SamplingResponse, error) {
+	if creator, ok := provider.(CapSamplingCreator); ok {
+		return creator.Sampling_OnCreateMessage(ctx, msg)
+	}
+	var result samplingResult
+	if err := provider.HandleRequest(&result, msg); err != nil {
+		return SamplingResponse{}, err
+	}
+	if result.errObj != nil {
+		return SamplingResponse{}, result.errObj
+	}
+	if !result.written {
+		return SamplingResponse{}, errObj(jsonrpc2.ErrObjInternalError)
+	}
+	return result.response, nil
+}
+
+// samplingResult captures the answer of the gated provider, to be reviewed
+// before being written out.
+type samplingResult struct {
+	response SamplingResponse
+	errObj   *jsonrpc2.ErrorObject
+	written  bool
+}
+
+func (r *samplingResult) WriteResponse(res SamplingResponse) error {
+	r.response, r.written = res, true
+	return nil
+}
+
+func (r *samplingResult) WriteError(errObj jsonrpc2.ErrorObject) error {
+	r.errObj, r.written = &errObj, true
+	return nil
+}
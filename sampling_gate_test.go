@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+func TestSamplingGate(t *testing.T) {
+	sampler := &selectingSampler{
+		selector: NewModelSelector(ModelInfo{Name: "claude-3-5-sonnet"}),
+		received: make(chan SamplingMessage, 1),
+	}
+	now := time.Date(2025, 1, 12, 15, 0, 0, 0, time.UTC)
+	type review struct {
+		server string
+		msg    SamplingMessage
+	}
+	reviews := make(chan review, 1)
+	gate := NewSamplingGate(sampler)
+	release := make(chan struct{})
+	gate.ReviewRequest = func(ctx context.Context, server string, msg SamplingMessage) (SamplingMessage, bool) {
+		if msg.SystemPrompt == "panic" {
+			panic("review failed")
+		}
+		reviews <- review{server, msg}
+		if msg.SystemPrompt == "hold" {
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return msg, false
+			}
+		}
+		if msg.SystemPrompt == "reject" {
+			return msg, false
+		}
+		msg.SystemPrompt = "edited"
+		return msg, true
+	}
+	gate.ReviewResponse = func(ctx context.Context, server string, msg SamplingMessage, res SamplingResponse) (SamplingResponse, bool) {
+		if msg.SystemPrompt != "edited" && msg.SystemPrompt != "hold" {
+			t.Errorf("Expected the edited request, got %+v", msg)
+		}
+		res.Content = TextContent("reviewed")
+		return res, true
+	}
+	gate.MaxTokens = 100
+	gate.RateLimit = 3
+	gate.RatePeriod = time.Minute
+	gate.now = func() time.Time { return now }
+
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+	}
+	clientInstance := &ClientImpl{
+		CapSamplingProvider: gate.ForServer("weather"),
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	message := func(systemPrompt string, maxTokens int64) SamplingMessage {
+		return SamplingMessage{
+			Messages:     []SamplingMessageItem{{Role: RoleUser, Content: TextContent("hi")}},
+			SystemPrompt: systemPrompt,
+			MaxTokens:    maxTokens,
+		}
+	}
+	expectRejected := func(t *testing.T, err error, message string) {
+		t.Helper()
+		rpcErr, ok := err.(*jsonrpc2.ErrorObject)
+		if !ok || rpcErr.Code != JSONRPC2SamplingRejected || rpcErr.Message != message {
+			t.Errorf("Expected %q, got %v", message, err)
+		}
+	}
+
+	t.Run("Approve", func(t *testing.T) {
+		response, err := ts.Server.CreateMessage(ts.Ctx, message("", 1000))
+		if err != nil {
+			t.Fatalf("CreateMessage failed: %v", err)
+		}
+		if response.Content.Text != "reviewed" {
+			t.Errorf("Unexpected response: %+v", response)
+		}
+		if r := <-reviews; r.server != "weather" || r.msg.MaxTokens != 100 {
+			t.Errorf("Unexpected review: %+v", r)
+		}
+		if received := <-sampler.received; received.SystemPrompt != "edited" || received.MaxTokens != 100 {
+			t.Errorf("Unexpected request: %+v", received)
+		}
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		_, err := ts.Server.CreateMessage(ts.Ctx, message("reject", 10))
+		expectRejected(t, err, kErrObjSamplingRejected.Message)
+		<-reviews
+	})
+
+	t.Run("RejectOverMaxTokens", func(t *testing.T) {
+		gate.RejectOverMaxTokens = true
+		defer func() { gate.RejectOverMaxTokens = false }()
+		_, err := ts.Server.CreateMessage(ts.Ctx, message("", 1000))
+		expectRejected(t, err, kErrObjSamplingTooManyTokens.Message)
+	})
+
+	t.Run("RateLimit", func(t *testing.T) {
+		_, err := ts.Server.CreateMessage(ts.Ctx, message("", 10))
+		expectRejected(t, err, kErrObjSamplingRateLimited.Message)
+
+		// Other servers have their own limit.
+		var result samplingResult
+		if err := gate.ForServer("search").HandleRequest(&result, message("", 10)); err != nil || result.errObj != nil {
+			t.Errorf("Expected request of another server to pass: %v, %v", err, result.errObj)
+		}
+		<-reviews
+		<-sampler.received
+
+		now = now.Add(time.Minute)
+		if _, err := ts.Server.CreateMessage(ts.Ctx, message("", 10)); err != nil {
+			t.Errorf("Expected request after the period to pass: %v", err)
+		}
+		<-reviews
+		<-sampler.received
+	})
+
+	t.Run("PendingReview", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		done := make(chan error, 1)
+		go func() {
+			_, err := ts.Server.CreateMessage(ts.Ctx, message("hold", 10))
+			done <- err
+		}()
+		<-reviews
+		// the client keeps answering the server during the review
+		if _, err := ts.Server.CreateMessage(ts.Ctx, message("", 10)); err != nil {
+			t.Errorf("CreateMessage failed during the review: %v", err)
+		}
+		<-reviews
+		<-sampler.received
+		close(release)
+		if err := <-done; err != nil {
+			t.Errorf("CreateMessage failed: %v", err)
+		}
+		<-sampler.received
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		_, err := ts.Server.CreateMessage(ts.Ctx, message("panic", 10))
+		rpcErr, ok := err.(*jsonrpc2.ErrorObject)
+		if !ok || rpcErr.Code != jsonrpc2.JSONRPC2ErrorInternalError || rpcErr.Data == nil {
+			t.Errorf("Expected an internal error with an incident, got %v", err)
+		}
+		if _, err := ts.Server.CreateMessage(ts.Ctx, message("", 10)); err != nil {
+			t.Errorf("CreateMessage failed after a panic: %v", err)
+		}
+		<-reviews
+		<-sampler.received
+	})
+
+	t.Run("DefaultRatePeriod", func(t *testing.T) {
+		limited := NewSamplingGate(sampler)
+		limited.ReviewRequest = func(ctx context.Context, server string, msg SamplingMessage) (SamplingMessage, bool) {
+			return msg, true
+		}
+		limited.RateLimit = 1
+		limited.now = func() time.Time { return now }
+		var result samplingResult
+		if limited.ForServer("weather").HandleRequest(&result, message("", 10)); result.errObj != nil {
+			t.Fatalf("Expected the first request to pass: %v", result.errObj)
+		}
+		<-sampler.received
+		result = samplingResult{}
+		limited.ForServer("weather").HandleRequest(&result, message("", 10))
+		if result.errObj == nil || result.errObj.Message != kErrObjSamplingRateLimited.Message {
+			t.Errorf("Expected the limit to apply without a period, got %v", result.errObj)
+		}
+	})
+
+	t.Run("NoReviewer", func(t *testing.T) {
+		var result samplingResult
+		err := NewSamplingGate(sampler).ForServer("weather").HandleRequest(&result, message("", 10))
+		if err != nil || result.errObj == nil || result.errObj.Message != kErrObjSamplingRejected.Message {
+			t.Errorf("Expected rejection without reviewer, got %v, %v", err, result.errObj)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+// selectingSampler answers sampling requests with the model chosen by its
+// selector, echoing the last message.
+type selectingSampler struct {
+	selector *ModelSelector
+	received chan SamplingMessage
+}
+
+func (c *selectingSampler) Sampling_Capability() *CapSampling {
+	return new(CapSampling)
+}
+
+func (c *selectingSampler) HandleRequest(w jsonrpc2.ResponseWriterOf[SamplingResponse], msg SamplingMessage) error {
+	c.received <- msg
+	model, _ := c.selector.Select(msg.ModelPreferences)
+	return w.WriteResponse(SamplingResponse{
+		Role:       RoleAssistant,
+		Content:    msg.Messages[len(msg.Messages)-1].Content,
+		Model:      model.Name,
+		StopReason: StopReasonEndTurn,
+	})
+}
+
+func TestSampling(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		data, err := json.Marshal(SamplingMessage{
+			Messages:  []SamplingMessageItem{{Role: RoleUser, Content: TextContent("hi")}},
+			MaxTokens: 100,
+		})
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		want := `{"messages":[{"role":"user","content":{"type":"text","text":"hi"}}],"maxTokens":100}`
+		if string(data) != want {
+			t.Errorf("Expected %s, got %s", want, data)
+		}
+
+		data, err = json.Marshal(SamplingMessageModelPreference{
+			Hints:         []SamplingMessageModelHint{{Name: "sonnet"}},
+			SpeedPriority: Priority(0),
+		})
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		want = `{"hints":[{"name":"sonnet"}],"speedPriority":0}`
+		if string(data) != want {
+			t.Errorf("Expected %s, got %s", want, data)
+		}
+	})
+
+	sampler := &selectingSampler{
+		selector: NewModelSelector(
+			ModelInfo{Name: "claude-3-5-sonnet", CostScore: 0.5, SpeedScore: 0.5, IntelligenceScore: 0.8},
+			ModelInfo{Name: "claude-3-haiku", CostScore: 0.9, SpeedScore: 0.9, IntelligenceScore: 0.4},
+			ModelInfo{Name: "claude-3-opus", CostScore: 0.1, SpeedScore: 0.2, IntelligenceScore: 1},
+		),
+		received: make(chan SamplingMessage, 1),
+	}
+
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+	}
+	clientInstance := &ClientImpl{
+		CapSamplingProvider: sampler,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("CreateMessage", func(t *testing.T) {
+		temperature := 0.2
+		msg := SamplingMessage{
+			Messages: []SamplingMessageItem{
+				{Role: RoleUser, Content: TextContent("Describe this image")},
+				{Role: RoleUser, Content: ImageContent([]byte("png"), "image/png")},
+			},
+			ModelPreferences: &SamplingMessageModelPreference{
+				Hints:        []SamplingMessageModelHint{{Name: "gpt-4"}, {Name: "claude-3"}},
+				CostPriority: Priority(1),
+			},
+			SystemPrompt:   "You are a helpful assistant.",
+			IncludeContext: IncludeContextThisServer,
+			Temperature:    &temperature,
+			MaxTokens:      100,
+			StopSequences:  []string{"\n\n"},
+			Metadata:       map[string]any{"user": "42"},
+		}
+		response, err := ts.Server.CreateMessage(ts.Ctx, msg)
+		if err != nil {
+			t.Fatalf("CreateMessage failed: %v", err)
+		}
+		if response.Model != "claude-3-haiku" || response.Content.Type != ContentTypeImage || response.StopReason != StopReasonEndTurn {
+			t.Errorf("Unexpected response: %+v", response)
+		}
+		received := <-sampler.received
+		if received.IncludeContext != IncludeContextThisServer || *received.Temperature != 0.2 ||
+			received.StopSequences[0] != "\n\n" || received.Metadata["user"] != "42" {
+			t.Errorf("Unexpected request: %+v", received)
+		}
+	})
+
+	t.Run("InvalidContent", func(t *testing.T) {
+		for _, content := range []Content{
+			{Type: ContentTypeImage},
+			ResourceLinkContent(ResourceSpec{URI: "file:///a", Name: "a"}),
+		} {
+			msg := SamplingMessage{Messages: []SamplingMessageItem{{Role: RoleUser, Content: content}}, MaxTokens: 10}
+			_, err := ts.Server.CreateMessage(ts.Ctx, msg)
+			if rpcErr, ok := err.(*jsonrpc2.ErrorObject); !ok || rpcErr.Code != jsonrpc2.JSONRPC2ErrorInvalidParams {
+				t.Errorf("Expected invalid params for %+v, got %v", content, err)
+			}
+		}
+	})
+}
+
+func TestModelSelector(t *testing.T) {
+	selector := NewModelSelector(
+		ModelInfo{Name: "claude-3-5-sonnet", CostScore: 0.5, SpeedScore: 0.5, IntelligenceScore: 0.8},
+		ModelInfo{Name: "claude-3-haiku", CostScore: 0.9, SpeedScore: 0.9, IntelligenceScore: 0.4},
+		ModelInfo{Name: "claude-3-opus", CostScore: 0.1, SpeedScore: 0.2, IntelligenceScore: 1},
+	)
+	hints := func(names ...string) []SamplingMessageModelHint {
+		var hints []SamplingMessageModelHint
+		for _, name := range names {
+			hints = append(hints, SamplingMessageModelHint{Name: name})
+		}
+		return hints
+	}
+	tests := []struct {
+		name  string
+		prefs *SamplingMessageModelPreference
+		want  string
+	}{
+		{"Default", nil, "claude-3-5-sonnet"},
+		{"NoPriorities", &SamplingMessageModelPreference{}, "claude-3-5-sonnet"},
+		{"Hint", &SamplingMessageModelPreference{Hints: hints("OPUS")}, "claude-3-opus"},
+		{"FirstMatchingHint", &SamplingMessageModelPreference{Hints: hints("gemini", "haiku", "opus")}, "claude-3-haiku"},
+		{"Speed", &SamplingMessageModelPreference{SpeedPriority: Priority(1)}, "claude-3-haiku"},
+		{"Intelligence", &SamplingMessageModelPreference{IntelligencePriority: Priority(1), CostPriority: Priority(0.3)}, "claude-3-opus"},
+		{"HintThenPriorities", &SamplingMessageModelPreference{Hints: hints("claude"), CostPriority: Priority(1)}, "claude-3-haiku"},
+		{"UnknownHint", &SamplingMessageModelPreference{Hints: hints("gpt-4"), IntelligencePriority: Priority(0.5)}, "claude-3-opus"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, ok := selector.Select(tt.prefs)
+			if !ok || model.Name != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, model.Name)
+			}
+		})
+	}
+
+	if _, ok := NewModelSelector().Select(nil); ok {
+		t.Error("Expected no model from an empty selector")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/vibeus/mcp/jsonrpc2"
@@ -12,17 +13,23 @@ import (
 var (
 	DefaultServerPingTimeout time.Duration = 5 * time.Second
 	DefaultServerRPCTimeout  time.Duration = 10 * time.Second
+	// sampling waits for a model and often for the user to review the request
+	DefaultServerSamplingTimeout time.Duration = 5 * time.Minute
 )
 
 type ServerTimeout struct {
 	PingTimeout time.Duration
 	RPCTimeout  time.Duration
+	// SamplingTimeout bounds the sampling requests whose context has no
+	// deadline.
+	SamplingTimeout time.Duration
 }
 
 var (
 	DefaultServerTimeout = ServerTimeout{
 		DefaultServerPingTimeout,
 		DefaultServerRPCTimeout,
+		DefaultServerSamplingTimeout,
 	}
 )
 
@@ -32,6 +39,9 @@ type ServerState struct {
 	rpc  *jsonrpc2.Peer
 
 	timeoutConfig ServerTimeout
+
+	subscriptionsMutex sync.Mutex
+	subscriptions      map[string]struct{}
 }
 
 func NewServer(conn io.ReadWriteCloser) *ServerState {
@@ -64,13 +74,20 @@ func (c *ServerState) Setup(impl ServerProvider) {
 	c.rpc = jsonrpc2.NewPeer(c.ctx, jsonrpc2.NewLineFramer(c.ctx.GetSession().GetConn()), impl)
 }
 
+// Done returns a channel closed when the connection to the client is lost, or
+// the session closed.
+func (c *ServerState) Done() <-chan struct{} {
+	return c.rpc.Done()
+}
+
 func (c *ServerState) Serve() error {
 	c.impl.BindState(c)
 	c.rpc.Start()
 	return nil
 }
 
-func (c *ServerState) NotifyPromptsListChanged(ctx context.Context) error {
+// notify sends a notification to the client.
+func (c *ServerState) notify(ctx context.Context, method string, params any) error {
 	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.PingTimeout)
 	defer cancel()
 
@@ -81,42 +98,102 @@ func (c *ServerState) NotifyPromptsListChanged(ctx context.Context) error {
 		s := c.ctx.GetSession()
 		logger := s.GetLogger()
 		if logger != nil {
-			logger.Debug("Notify", "method", kMethodPromptsListChanged)
+			logger.Debug("Notify", "method", method)
 		}
-		return c.rpc.Notify(kMethodPromptsListChanged, nil)
+		return c.rpc.Notify(method, params)
 	}
 }
 
-func (c *ServerState) NotifyToolsListChanged(ctx context.Context) error {
-	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.PingTimeout)
-	defer cancel()
+// CreateMessage asks the client to sample a message from a model. It waits
+// until the deadline of ctx, or for the sampling timeout if ctx has none.
+func (c *ServerState) CreateMessage(ctx context.Context, msg SamplingMessage) (SamplingResponse, error) {
+	s := c.ctx.GetSession()
+	cc := s.GetClientCapabilities()
+	if cc == nil || cc.Sampling == nil {
+		return SamplingResponse{}, jsonrpc2.ErrObjMethodNotSupported
+	}
 
-	select {
-	case <-to_ctx.Done():
-		return to_ctx.Err()
-	default:
-		s := c.ctx.GetSession()
-		logger := s.GetLogger()
-		if logger != nil {
-			logger.Debug("Notify", "method", kMethodToolsListChanged)
-		}
-		return c.rpc.Notify(kMethodToolsListChanged, nil)
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeoutConfig.SamplingTimeout)
+		defer cancel()
+	}
+	logger := s.GetLogger()
+	if logger != nil {
+		logger.Debug("Call", "method", kMethodSamplingCreateMessage)
 	}
+	return jsonrpc2.CallOf[SamplingMessage, SamplingResponse](ctx, c.rpc, kMethodSamplingCreateMessage, msg)
+}
+
+func (c *ServerState) NotifyPromptsListChanged(ctx context.Context) error {
+	return c.notify(ctx, kMethodPromptsListChanged, nil)
+}
+
+func (c *ServerState) NotifyToolsListChanged(ctx context.Context) error {
+	return c.notify(ctx, kMethodToolsListChanged, nil)
 }
 
 func (c *ServerState) NotifyResourcesListChanged(ctx context.Context) error {
-	to_ctx, cancel := context.WithTimeout(ctx, c.timeoutConfig.PingTimeout)
-	defer cancel()
+	return c.notify(ctx, kMethodResourcesListChanged, nil)
+}
 
-	select {
-	case <-to_ctx.Done():
-		return to_ctx.Err()
-	default:
-		s := c.ctx.GetSession()
-		logger := s.GetLogger()
-		if logger != nil {
-			logger.Debug("Notify", "method", kMethodResourcesListChanged)
-		}
-		return c.rpc.Notify(kMethodResourcesListChanged, nil)
+// NotifyResourceUpdated tells the client that the content of the resource at
+// uri has changed.
+func (c *ServerState) NotifyResourceUpdated(ctx context.Context, uri string) error {
+	return c.notify(ctx, kMethodResourcesUpdated, ResourceUpdatedNotification{URI: uri})
+}
+
+// NotifyLogMessage sends a log message to the client.
+func (c *ServerState) NotifyLogMessage(ctx context.Context, msg LogMessageNotification) error {
+	return c.notify(ctx, kMethodLoggingMessage, msg)
+}
+
+// NotifyProgress reports the progress of a long-running request to the client.
+func (c *ServerState) NotifyProgress(ctx context.Context, progress ProgressNotification) error {
+	return c.notify(ctx, kMethodProgress, progress)
+}
+
+// IsSubscribed reports whether the client subscribed to updates of the
+// resource at uri.
+func (c *ServerState) IsSubscribed(uri string) bool {
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+	_, ok := c.subscriptions[uri]
+	return ok
+}
+
+func (c *ServerState) subscribe(uri string) {
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+	if c.subscriptions == nil {
+		c.subscriptions = make(map[string]struct{})
+	}
+	c.subscriptions[uri] = struct{}{}
+}
+
+func (c *ServerState) unsubscribe(uri string) {
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+	delete(c.subscriptions, uri)
+}
+
+// resourceChanges is the [ResourceChangeReporter] of a server.
+type resourceChanges struct {
+	server *ServerState
+}
+
+func (r resourceChanges) ListChanged() {
+	r.server.NotifyResourcesListChanged(r.server.ctx)
+}
+
+func (r resourceChanges) Updated(uri string) {
+	if r.server.IsSubscribed(uri) {
+		r.server.NotifyResourceUpdated(r.server.ctx, uri)
+	}
+}
+
+func (r resourceChanges) Failed(err error) {
+	if logger := r.server.ctx.GetSession().GetLogger(); logger != nil {
+		logger.Error("Failed to watch resources", "error", err)
 	}
 }
@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
 	"sync"
 
@@ -26,7 +27,8 @@ type ServerImpl struct {
 	CapToolsProvider
 	CapResourcesProvider // Add resources capability provider
 
-	once sync.Once
+	interceptors []Interceptor
+	once         sync.Once
 }
 
 func (c *ServerImpl) BindState(server *ServerState) {
@@ -46,6 +48,9 @@ func (c *ServerImpl) StartServerProvider() {
 		}
 		if c.CapResourcesProvider != nil { // Start resources capability
 			startCapResources(c.server, c.CapResourcesProvider)
+			if watcher, ok := c.CapResourcesProvider.(CapResourcesWatcher); ok {
+				go watcher.Resources_Watch(c.server.ctx, resourceChanges{c.server})
+			}
 		}
 	})
 }
@@ -68,145 +73,63 @@ func (c *ServerImpl) HandleNotification(req jsonrpc2.Request) error {
 	return nil
 }
 
+// Use appends interceptors wrapping the dispatch of every incoming MCP request
+// and notification. Interceptors must be installed before serving.
+func (c *ServerImpl) Use(interceptors ...Interceptor) {
+	c.interceptors = append(c.interceptors, interceptors...)
+}
+
 func (c *ServerImpl) HandleRequest(w *jsonrpc2.ResponseWriter, req jsonrpc2.Request) error {
 	s := c.server.ctx.GetSession()
+
+	var params any
+	var handler MethodHandler
+	var erro *jsonrpc2.ErrorObject
 	switch s.GetMCPState() {
 	case MCPState_Start:
-		err := c.handleStart(w, req)
-		if err != nil {
-			return err
-		}
+		params, handler, erro = c.handleStart(req)
 	case MCPState_Initializing:
-		err := c.handleInitializing(w, req)
-		if err != nil {
-			return err
-		}
+		params, handler, erro = c.handleInitializing(req)
 	case MCPState_Initialized:
-		switch req.Method {
-		case kMethodPromptsList:
-			if c.CapPromptsProvider != nil {
-				var msg PagedRequest
-				err := json.Unmarshal(*req.Params, &msg)
-				if err != nil {
-					w.WriteError(jsonrpc2.ErrObjInvalidParams)
-					return nil
-				}
-				prompts := c.CapPromptsProvider.Prompts_OnList(msg.Cursor)
-				w.WriteResponse(prompts)
-			} else {
-				w.WriteError(jsonrpc2.ErrObjMethodNotSupported)
-			}
-			return nil
-		case kMethodPromptsGet:
-			if c.CapPromptsProvider != nil {
-				var msg PromptGetRequest
-				err := json.Unmarshal(*req.Params, &msg)
-				if err != nil {
-					w.WriteError(jsonrpc2.ErrObjInvalidParams)
-					return nil
-				}
-				promptName := msg.Name
-				response, erro := c.CapPromptsProvider.Prompts_OnGet(promptName)
-				if erro != nil {
-					w.WriteError(*erro)
-					return nil
-				}
-				w.WriteResponse(response)
-			} else {
-				w.WriteError(jsonrpc2.ErrObjMethodNotSupported)
-			}
-			return nil
-		case kMethodToolsList:
-			if c.CapToolsProvider != nil {
-				var msg PagedRequest
-				err := json.Unmarshal(*req.Params, &msg)
-				if err != nil {
-					w.WriteError(jsonrpc2.ErrObjInvalidParams)
-					return nil
-				}
-				tools := c.CapToolsProvider.Tools_OnList(msg.Cursor)
-				w.WriteResponse(tools)
-			} else {
-				w.WriteError(jsonrpc2.ErrObjMethodNotSupported)
-			}
-			return nil
-		case kMethodToolsCall:
-			if c.CapToolsProvider != nil {
-				var msg ToolCallRequest
-				err := json.Unmarshal(*req.Params, &msg)
-				if err != nil {
-					w.WriteError(jsonrpc2.ErrObjInvalidParams)
-					return nil
-				}
-				response, erro := c.CapToolsProvider.Tools_OnCall(msg.Name, msg.Arguments)
-				if erro != nil {
-					w.WriteError(*erro)
-					return nil
-				}
-				w.WriteResponse(response)
-			} else {
-				w.WriteError(jsonrpc2.ErrObjMethodNotSupported)
-			}
-			return nil
-		case kMethodResourcesList:
-			if c.CapResourcesProvider != nil { // Add resources capability
-				var msg PagedRequest
-				err := json.Unmarshal(*req.Params, &msg)
-				if err != nil {
-					w.WriteError(jsonrpc2.ErrObjInvalidParams)
-					return nil
-				}
-				resources := c.CapResourcesProvider.Resources_OnList(msg.Cursor)
-				response := ResourcesListResponse{}
-				response.Resources = resources
-				w.WriteResponse(response)
-			} else {
-				w.WriteError(jsonrpc2.ErrObjMethodNotSupported)
-			}
-			return nil
-		case kMethodResourcesTemplatesList:
-			if c.CapResourcesProvider != nil { // Add resources capability
-				resources := c.CapResourcesProvider.Resources_OnTemplatesList()
-				response := ResourcesTemplatesListResponse{}
-				response.ResourceTemplates = resources
-				w.WriteResponse(response)
-			} else {
-				w.WriteError(jsonrpc2.ErrObjMethodNotSupported)
-			}
-			return nil
-		case kMethodResourcesRead:
-			if c.CapResourcesProvider != nil { // Add resources capability
-				var msg ResourcesReadRequest
-				err := json.Unmarshal(*req.Params, &msg)
-				if err != nil {
-					w.WriteError(jsonrpc2.ErrObjInvalidParams)
-					return nil
-				}
-				uri := msg.URI
-				response := ResourcesReadResponse{}
-				response.Content = c.CapResourcesProvider.Resources_OnRead(uri)
-				if len(response.Content) == 0 {
-					obj := kErrObjResourceNotFound
-					var data struct {
-						Uri string `json:"uri"`
-					}
-					data.Uri = uri
-					datajson, _ := json.Marshal(data)
-					obj.Data.UnmarshalJSON(datajson)
-					w.WriteError(obj)
-				} else {
-					w.WriteResponse(response)
-				}
-			} else {
-				w.WriteError(jsonrpc2.ErrObjMethodNotSupported)
-			}
-			return nil
+		params, handler, erro = c.handleInitialized(req)
+	default:
+		erro = errObj(jsonrpc2.ErrObjInvalidRequest)
+	}
+	if erro != nil {
+		params, handler = nil, func(ctx context.Context, inv *Invocation) (any, error) {
+			return nil, erro
 		}
 	}
+
+	inv := &Invocation{Method: req.Method, Params: params, Session: s}
+	result, err := chainInterceptors(c.interceptors, handler)(c.server.ctx, inv)
+	if req.IsNotification() {
+		return nil
+	}
+	if err != nil {
+		return w.WriteError(toErrorObject(err, s.GetLogger()))
+	}
+	return w.WriteResponse(result)
+}
+
+// decodeParams decodes the parameters of req into v. Omitted parameters leave
+// v untouched.
+func decodeParams(req jsonrpc2.Request, v any) *jsonrpc2.ErrorObject {
+	if req.Params == nil {
+		return nil
+	}
+	err := json.Unmarshal(*req.Params, v)
+	if err != nil {
+		return errObj(jsonrpc2.ErrObjInvalidParams)
+	}
 	return nil
 }
 
-func (c *ServerImpl) handleStart(w *jsonrpc2.ResponseWriter, req jsonrpc2.Request) error {
+func (c *ServerImpl) handlePing(ctx context.Context, inv *Invocation) (any, error) {
+	return struct{}{}, nil
+}
+
+func (c *ServerImpl) handleStart(req jsonrpc2.Request) (any, MethodHandler, *jsonrpc2.ErrorObject) {
 	s := c.server.ctx.GetSession()
 	logger := s.GetLogger()
 	if logger != nil {
@@ -215,45 +138,256 @@ func (c *ServerImpl) handleStart(w *jsonrpc2.ResponseWriter, req jsonrpc2.Reques
 
 	switch req.Method {
 	case kMethodPing:
-		return w.WriteResponse(nil)
+		return nil, c.handlePing, nil
 	case kMethodInitialize:
 		ci := new(ClientInitializeInfo)
-
-		err := json.Unmarshal(*req.Params, ci)
-		if err != nil {
-			return err
+		if erro := decodeParams(req, ci); erro != nil {
+			return nil, nil, erro
 		}
+		return ci, func(ctx context.Context, inv *Invocation) (any, error) {
+			s.SetMCPState(MCPState_Initializing)
+			s.SetClientCapabilities(&ci.Capabilities)
+			s.SetClientInfo(&ci.ClientInfo)
 
-		s.SetMCPState(MCPState_Initializing)
-		s.SetClientCapabilities(&ci.Capabilities)
-		s.SetClientInfo(&ci.ClientInfo)
-
-		version := c.MCPVersionNegotiator.NegotiateMCPVersion(ci.ProtocolVersion)
-		s.SetProtocolVersion(version)
+			version := c.MCPVersionNegotiator.NegotiateMCPVersion(ci.ProtocolVersion)
+			s.SetProtocolVersion(version)
 
-		si := new(ServerInitializeInfo)
-		si.ProtocolVersion = version
-		si.Capabilities = *s.GetServerCapabilities()
-		si.ServerInfo = *s.GetServerInfo()
-		return w.WriteResponse(si)
+			si := new(ServerInitializeInfo)
+			si.ProtocolVersion = version
+			si.Capabilities = *s.GetServerCapabilities()
+			si.ServerInfo = *s.GetServerInfo()
+			return si, nil
+		}, nil
 	default:
-		return w.WriteError(jsonrpc2.ErrObjMethodNotSupported)
+		return nil, nil, errObj(jsonrpc2.ErrObjMethodNotSupported)
 	}
 }
 
-func (c *ServerImpl) handleInitializing(w *jsonrpc2.ResponseWriter, req jsonrpc2.Request) error {
+func (c *ServerImpl) handleInitializing(req jsonrpc2.Request) (any, MethodHandler, *jsonrpc2.ErrorObject) {
 	switch req.Method {
 	case kMethodPing:
-		return w.WriteResponse(nil)
+		return nil, c.handlePing, nil
 	case kMethodInitialized:
 		if !req.IsNotification() {
-			return w.WriteError(jsonrpc2.ErrObjInvalidRequest)
+			return nil, nil, errObj(jsonrpc2.ErrObjInvalidRequest)
 		}
-		s := c.server.ctx.GetSession()
-		c.StartServerProvider()
-		s.SetMCPState(MCPState_Initialized)
-		return nil
+		return nil, func(ctx context.Context, inv *Invocation) (any, error) {
+			s := c.server.ctx.GetSession()
+			c.StartServerProvider()
+			s.SetMCPState(MCPState_Initialized)
+			return nil, nil
+		}, nil
 	default:
-		return w.WriteError(jsonrpc2.ErrObjMethodNotSupported)
+		return nil, nil, errObj(jsonrpc2.ErrObjMethodNotSupported)
+	}
+}
+
+func (c *ServerImpl) handleInitialized(req jsonrpc2.Request) (any, MethodHandler, *jsonrpc2.ErrorObject) {
+	switch req.Method {
+	case kMethodPing:
+		return nil, c.handlePing, nil
+	case kMethodPromptsList:
+		if c.CapPromptsProvider == nil {
+			break
+		}
+		msg := new(PagedRequest)
+		if erro := decodeParams(req, msg); erro != nil {
+			return nil, nil, erro
+		}
+		return msg, func(ctx context.Context, inv *Invocation) (any, error) {
+			return c.CapPromptsProvider.Prompts_OnList(msg.Cursor), nil
+		}, nil
+	case kMethodPromptsGet:
+		if c.CapPromptsProvider == nil {
+			break
+		}
+		msg := new(PromptGetRequest)
+		if erro := decodeParams(req, msg); erro != nil {
+			return nil, nil, erro
+		}
+		return msg, func(ctx context.Context, inv *Invocation) (any, error) {
+			if spec, ok := findPrompt(c.CapPromptsProvider, msg.Name); ok {
+				if erro := checkPromptArguments(spec, msg.Arguments); erro != nil {
+					return nil, erro
+				}
+			}
+			response, erro := c.CapPromptsProvider.Prompts_OnGet(msg.Name, msg.Arguments)
+			if erro != nil {
+				return nil, erro
+			}
+			return response, nil
+		}, nil
+	case kMethodToolsList:
+		if c.CapToolsProvider == nil {
+			break
+		}
+		msg := new(PagedRequest)
+		if erro := decodeParams(req, msg); erro != nil {
+			return nil, nil, erro
+		}
+		return msg, func(ctx context.Context, inv *Invocation) (any, error) {
+			if lister, ok := c.CapToolsProvider.(CapToolsLister); ok {
+				pages, erro := lister.Tools_List(msg.Cursor)
+				if erro != nil {
+					return nil, erro
+				}
+				return pages, nil
+			}
+			return c.CapToolsProvider.Tools_OnList(msg.Cursor), nil
+		}, nil
+	case kMethodToolsCall:
+		if c.CapToolsProvider == nil {
+			break
+		}
+		msg := new(ToolCallRequest)
+		if erro := decodeParams(req, msg); erro != nil {
+			return nil, nil, erro
+		}
+		return msg, func(ctx context.Context, inv *Invocation) (any, error) {
+			response, erro := c.CapToolsProvider.Tools_OnCall(msg.Name, msg.Arguments)
+			if erro != nil {
+				return nil, erro
+			}
+			return response, nil
+		}, nil
+	case kMethodResourcesList:
+		if c.CapResourcesProvider == nil {
+			break
+		}
+		msg := new(PagedRequest)
+		if erro := decodeParams(req, msg); erro != nil {
+			return nil, nil, erro
+		}
+		return msg, func(ctx context.Context, inv *Invocation) (any, error) {
+			response := ResourcesListResponse{}
+			response.Resources = c.CapResourcesProvider.Resources_OnList(msg.Cursor)
+			return response, nil
+		}, nil
+	case kMethodResourcesTemplatesList:
+		if c.CapResourcesProvider == nil {
+			break
+		}
+		msg := new(PagedRequest)
+		if erro := decodeParams(req, msg); erro != nil {
+			return nil, nil, erro
+		}
+		return msg, func(ctx context.Context, inv *Invocation) (any, error) {
+			if pager, ok := c.CapResourcesProvider.(CapResourceTemplatesPager); ok {
+				response, erro := pager.Resources_OnTemplatesListPage(msg.Cursor)
+				if erro != nil {
+					return nil, erro
+				}
+				return response, nil
+			}
+			response := ResourcesTemplatesListResponse{}
+			response.ResourceTemplates = c.CapResourcesProvider.Resources_OnTemplatesList()
+			return response, nil
+		}, nil
+	case kMethodResourcesRead:
+		if c.CapResourcesProvider == nil {
+			break
+		}
+		msg := new(ResourcesReadRequest)
+		if erro := decodeParams(req, msg); erro != nil {
+			return nil, nil, erro
+		}
+		return msg, func(ctx context.Context, inv *Invocation) (any, error) {
+			response := ResourcesReadResponse{}
+			if reader, ok := c.CapResourcesProvider.(CapResourcesReader); ok {
+				content, erro := reader.Resources_Read(msg.URI)
+				if erro != nil {
+					return nil, erro
+				}
+				response.Content = content
+			} else {
+				response.Content = c.CapResourcesProvider.Resources_OnRead(msg.URI)
+			}
+			if len(response.Content) == 0 {
+				return nil, errResourceNotFound(msg.URI)
+			}
+			return response, nil
+		}, nil
+	case kMethodResourcesSubscribe, kMethodResourcesUnsubscribe:
+		if c.CapResourcesProvider == nil || !c.CapResourcesProvider.Resources_Capability().Subscribe {
+			break
+		}
+		msg := new(ResourcesSubscribeRequest)
+		if erro := decodeParams(req, msg); erro != nil {
+			return nil, nil, erro
+		}
+		return msg, func(ctx context.Context, inv *Invocation) (any, error) {
+			if inv.Method == kMethodResourcesSubscribe {
+				c.server.subscribe(msg.URI)
+			} else {
+				c.server.unsubscribe(msg.URI)
+			}
+			return struct{}{}, nil
+		}, nil
+	}
+	return nil, nil, errObj(jsonrpc2.ErrObjMethodNotSupported)
+}
+
+// findPrompt looks up the prompt name with the finder of prompts, if it
+// implements [CapPromptsFinder], or across the pages it lists otherwise.
+func findPrompt(prompts CapPromptsProvider, name string) (PromptSpec, bool) {
+	if finder, ok := prompts.(CapPromptsFinder); ok {
+		return finder.Prompts_Find(name)
+	}
+	seen := make(map[string]bool)
+	cursor := ""
+	for {
+		pages := prompts.Prompts_OnList(cursor)
+		next := ""
+		for _, page := range pages {
+			for _, spec := range page.Prompts {
+				if spec.Name == name {
+					return spec, true
+				}
+			}
+			next = page.NextCursor
+		}
+		if next == "" || seen[next] {
+			return PromptSpec{}, false
+		}
+		seen[next] = true
+		cursor = next
+	}
+}
+
+// checkPromptArguments reports the required arguments of spec missing from
+// args with [jsonrpc2.ErrObjInvalidParams].
+func checkPromptArguments(spec PromptSpec, args map[string]string) *jsonrpc2.ErrorObject {
+	var missing []string
+	for _, arg := range spec.Arguments {
+		if _, ok := args[arg.Name]; arg.Required && !ok {
+			missing = append(missing, arg.Name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	obj := jsonrpc2.ErrObjInvalidParams
+	var data struct {
+		Missing []string `json:"missing"`
+	}
+	data.Missing = missing
+	datajson, _ := json.Marshal(data)
+	obj.Data = (*json.RawMessage)(&datajson)
+	return &obj
+}
+
+// errObj returns a pointer to a copy of obj.
+func errObj(obj jsonrpc2.ErrorObject) *jsonrpc2.ErrorObject {
+	return &obj
+}
+
+func errResourceNotFound(uri string) *jsonrpc2.ErrorObject {
+	obj := kErrObjResourceNotFound
+	var data struct {
+		Uri string `json:"uri"`
 	}
+	data.Uri = uri
+	datajson, _ := json.Marshal(data)
+	obj.Data = (*json.RawMessage)(&datajson)
+	return &obj
 }
@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"io"
+	"sync"
 
 	"log/slog"
 
@@ -97,6 +98,10 @@ type session struct {
 	clientCaps      *ClientCapabilities
 	cancel          context.CancelFunc
 	mcpState        MCPState
+
+	// guards the fields above, which are accessed from the goroutines serving
+	// requests and sending notifications
+	mutex sync.RWMutex
 }
 
 func (s *session) Init(ctx context.Context, conn io.ReadWriteCloser) SessionContext {
@@ -119,67 +124,99 @@ func (s *session) Close() {
 	s.cancel()
 }
 
-func (s session) SessionID() string {
+func (s *session) SessionID() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return s.id
 }
 
-func (s session) GetConn() io.ReadWriteCloser {
+func (s *session) GetConn() io.ReadWriteCloser {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return s.conn
 }
 
-func (s session) GetLogger() *slog.Logger {
+func (s *session) GetLogger() *slog.Logger {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return s.logger
 }
 
 func (s *session) SetLogger(logger *slog.Logger) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.logger = logger
 }
 
-func (s session) GetProtocolVersion() string {
+func (s *session) GetProtocolVersion() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return s.protocolVersion
 }
 
 func (s *session) SetProtocolVersion(version string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.protocolVersion = version
 }
 
-func (s session) GetServerInfo() *ServerInfo {
+func (s *session) GetServerInfo() *ServerInfo {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return s.serverInfo
 }
 
 func (s *session) SetServerInfo(si *ServerInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.serverInfo = si
 }
 
-func (s session) GetClientInfo() *ClientInfo {
+func (s *session) GetClientInfo() *ClientInfo {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return s.clientInfo
 }
 
 func (s *session) SetClientInfo(ci *ClientInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.clientInfo = ci
 }
 
-func (s session) GetServerCapabilities() *ServerCapabilities {
+func (s *session) GetServerCapabilities() *ServerCapabilities {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return s.serverCaps
 }
 
 func (s *session) SetServerCapabilities(sc *ServerCapabilities) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.serverCaps = sc
 }
 
-func (s session) GetClientCapabilities() *ClientCapabilities {
+func (s *session) GetClientCapabilities() *ClientCapabilities {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return s.clientCaps
 }
 
 func (s *session) SetClientCapabilities(cc *ClientCapabilities) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.clientCaps = cc
 }
 
-func (s session) GetMCPState() MCPState {
+func (s *session) GetMCPState() MCPState {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return s.mcpState
 }
 
 func (s *session) SetMCPState(ms MCPState) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	if s.logger != nil {
 		s.logger.Debug("Setting MCP state", "state", ms.String())
 	}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrToolNotFound     = errors.New("mcp: tool not found")
+	ErrToolCallRejected = errors.New("mcp: tool call rejected")
+)
+
+// ToolAnnotations are hints describing the behavior of a tool. They are not
+// guaranteed to be accurate, and clients should not rely on them for tools of
+// untrusted servers. Unset hints take the defaults of the MCP specification,
+// as returned by the Is* methods.
+type ToolAnnotations struct {
+	// human readable name, preferred to ToolSpec.Title for display
+	Title string `json:"title,omitempty"`
+	// whether the tool does not modify its environment, false by default
+	ReadOnlyHint *bool `json:"readOnlyHint,omitempty"`
+	// whether the tool may perform destructive updates, true by default;
+	// meaningful only if the tool is not read-only
+	DestructiveHint *bool `json:"destructiveHint,omitempty"`
+	// whether calling the tool repeatedly with the same arguments has no
+	// additional effect, false by default; meaningful only if the tool is not
+	// read-only
+	IdempotentHint *bool `json:"idempotentHint,omitempty"`
+	// whether the tool interacts with an open world of external entities,
+	// true by default
+	OpenWorldHint *bool `json:"openWorldHint,omitempty"`
+}
+
+// Bool returns a pointer to v, to set the hints of [ToolAnnotations].
+func Bool(v bool) *bool {
+	return &v
+}
+
+func hint(v *bool, def bool) bool {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+func (a *ToolAnnotations) IsReadOnly() bool {
+	return a != nil && hint(a.ReadOnlyHint, false)
+}
+
+func (a *ToolAnnotations) IsDestructive() bool {
+	return !a.IsReadOnly() && (a == nil || hint(a.DestructiveHint, true))
+}
+
+func (a *ToolAnnotations) IsIdempotent() bool {
+	return !a.IsReadOnly() && a != nil && hint(a.IdempotentHint, false)
+}
+
+func (a *ToolAnnotations) IsOpenWorld() bool {
+	return a == nil || hint(a.OpenWorldHint, true)
+}
+
+// DisplayName returns the name of the tool to show to users: the title of its
+// annotations, its title, or its name.
+func (t ToolSpec) DisplayName() string {
+	if t.Annotations != nil && t.Annotations.Title != "" {
+		return t.Annotations.Title
+	}
+	if t.Title != "" {
+		return t.Title
+	}
+	return t.Name
+}
+
+// FindTool returns the tool of the server named name, or [ErrToolNotFound].
+// The tools are taken from the catalog cache if enabled, and listed from the
+// server on each call otherwise.
+func (c *ClientState) FindTool(ctx context.Context, name string) (ToolSpec, error) {
+	if c.catalog != nil {
+		tools, err := c.catalog.Tools(ctx)
+		if err != nil {
+			return ToolSpec{}, err
+		}
+		for _, tool := range tools {
+			if tool.Name == name {
+				return tool, nil
+			}
+		}
+		return ToolSpec{}, ErrToolNotFound
+	}
+	for tool, err := range c.AllTools(ctx) {
+		if err != nil {
+			return ToolSpec{}, err
+		}
+		if tool.Name == name {
+			return tool, nil
+		}
+	}
+	return ToolSpec{}, ErrToolNotFound
+}
+
+// ToolApprovalPolicy decides whether the calls of a client to tools may
+// proceed, from the annotations of the tools. Calls to read-only tools are
+// approved, and calls to destructive tools, including tools without
+// annotations, need a confirmation.
+type ToolApprovalPolicy struct {
+	// Confirm asks whether a call needing a confirmation may proceed. Calls
+	// needing a confirmation are rejected if it is nil.
+	Confirm func(ctx context.Context, tool ToolSpec, args map[string]string) bool
+	// ConfirmWrites also asks for a confirmation of the calls to tools that
+	// are neither read-only nor destructive, which are approved otherwise.
+	ConfirmWrites bool
+}
+
+// NeedsConfirmation reports whether a call to tool needs a confirmation.
+func (p *ToolApprovalPolicy) NeedsConfirmation(tool ToolSpec) bool {
+	if tool.Annotations.IsReadOnly() {
+		return false
+	}
+	return tool.Annotations.IsDestructive() || p.ConfirmWrites
+}
+
+// Interceptor returns a client [Interceptor] enforcing the policy on the
+// tools/call requests of c, which fail with [ErrToolCallRejected] when they
+// are not approved. Tools are looked up with [ClientState.FindTool] in the
+// catalog cache of c, which it enables, so it must be called before the client
+// is initialized; unknown tools need a confirmation.
+func (p *ToolApprovalPolicy) Interceptor(c *ClientState) Interceptor {
+	c.EnableCatalogCache()
+	return func(ctx context.Context, inv *Invocation, next MethodHandler) (any, error) {
+		req, ok := inv.Params.(*ToolCallRequest)
+		if inv.Method != kMethodToolsCall || !ok {
+			return next(ctx, inv)
+		}
+		tool, err := c.FindTool(ctx, req.Name)
+		if err != nil && !errors.Is(err, ErrToolNotFound) {
+			return nil, err
+		}
+		if err != nil {
+			tool = ToolSpec{Name: req.Name}
+		}
+		if p.NeedsConfirmation(tool) && (p.Confirm == nil || !p.Confirm(ctx, tool, req.Arguments)) {
+			return nil, ErrToolCallRejected
+		}
+		return next(ctx, inv)
+	}
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestToolAnnotations(t *testing.T) {
+	var nilAnnotations *ToolAnnotations
+	if nilAnnotations.IsReadOnly() || !nilAnnotations.IsDestructive() || nilAnnotations.IsIdempotent() || !nilAnnotations.IsOpenWorld() {
+		t.Error("Unexpected defaults for nil annotations")
+	}
+	readOnly := &ToolAnnotations{ReadOnlyHint: Bool(true), DestructiveHint: Bool(true)}
+	if !readOnly.IsReadOnly() || readOnly.IsDestructive() {
+		t.Error("Expected read-only tool not to be destructive")
+	}
+
+	tools := []ToolSpec{
+		{Name: "read_file", Title: "Read file", Annotations: &ToolAnnotations{ReadOnlyHint: Bool(true)}},
+		{Name: "delete_file", Annotations: &ToolAnnotations{Title: "Delete file", DestructiveHint: Bool(true)}},
+		{Name: "append_log", Annotations: &ToolAnnotations{DestructiveHint: Bool(false), IdempotentHint: Bool(false)}},
+		{Name: "legacy", Meta: map[string]any{"version": "1"}},
+	}
+
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapToolsProvider:     serverProvider,
+	}
+	serverInstance.Use(func(ctx context.Context, inv *Invocation, next MethodHandler) (any, error) {
+		switch inv.Method {
+		case kMethodToolsList:
+			return []ListToolsResonponse{{Tools: tools}}, nil
+		case kMethodToolsCall:
+			return ToolCallResponse{Content: []Content{TextContent("called " + inv.Params.(*ToolCallRequest).Name)}}, nil
+		}
+		return next(ctx, inv)
+	})
+	clientProvider := &testClientImpl{roots_ListChanged: make(chan struct{})}
+	clientInstance := &ClientImpl{
+		CapRootsProvider:    clientProvider,
+		CapSamplingProvider: clientProvider,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	var confirmed []string
+	approve := false
+	policy := &ToolApprovalPolicy{
+		Confirm: func(ctx context.Context, tool ToolSpec, args map[string]string) bool {
+			confirmed = append(confirmed, tool.DisplayName())
+			return approve
+		},
+	}
+	ts.Client.Use(policy.Interceptor(ts.Client))
+	if ts.Client.Catalog() == nil {
+		t.Error("Expected the interceptor to enable the catalog cache")
+	}
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("FindTool", func(t *testing.T) {
+		tool, err := ts.Client.FindTool(ts.Ctx, "read_file")
+		if err != nil {
+			t.Fatalf("FindTool failed: %v", err)
+		}
+		if tool.Title != "Read file" || !tool.Annotations.IsReadOnly() {
+			t.Errorf("Unexpected tool: %+v", tool)
+		}
+		tool, err = ts.Client.FindTool(ts.Ctx, "legacy")
+		if err != nil || tool.Meta["version"] != "1" || tool.Annotations != nil {
+			t.Errorf("Unexpected tool: %+v, %v", tool, err)
+		}
+		if _, err := ts.Client.FindTool(ts.Ctx, "missing"); !errors.Is(err, ErrToolNotFound) {
+			t.Errorf("Expected ErrToolNotFound, got %v", err)
+		}
+	})
+
+	call := func(name string) error {
+		_, err := ts.Client.ToolCall(ts.Ctx, name, nil)
+		return err
+	}
+
+	t.Run("Policy", func(t *testing.T) {
+		if err := call("read_file"); err != nil {
+			t.Errorf("Expected read-only tool to be approved, got %v", err)
+		}
+		if err := call("append_log"); err != nil {
+			t.Errorf("Expected non-destructive tool to be approved, got %v", err)
+		}
+		if len(confirmed) != 0 {
+			t.Errorf("Unexpected confirmations: %v", confirmed)
+		}
+
+		for _, name := range []string{"delete_file", "legacy", "missing"} {
+			if err := call(name); !errors.Is(err, ErrToolCallRejected) {
+				t.Errorf("Expected call to %q to be rejected, got %v", name, err)
+			}
+		}
+		approve = true
+		if err := call("delete_file"); err != nil {
+			t.Errorf("Expected confirmed call to proceed, got %v", err)
+		}
+		want := []string{"Delete file", "legacy", "missing", "Delete file"}
+		if len(confirmed) != len(want) {
+			t.Fatalf("Expected confirmations %v, got %v", want, confirmed)
+		}
+		for i := range want {
+			if confirmed[i] != want[i] {
+				t.Errorf("Expected confirmations %v, got %v", want, confirmed)
+				break
+			}
+		}
+	})
+
+	t.Run("ConfirmWrites", func(t *testing.T) {
+		policy.ConfirmWrites = true
+		defer func() { policy.ConfirmWrites = false }()
+		if !policy.NeedsConfirmation(tools[2]) || policy.NeedsConfirmation(tools[0]) {
+			t.Error("Expected writes but not reads to need a confirmation")
+		}
+	})
+}
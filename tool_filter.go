@@ -0,0 +1,417 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"sync"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+// ToolFilter curates a set of tools: tools are allowed or denied by name, and
+// renamed, described and restricted anew without changing their server.
+type ToolFilter struct {
+	// Allow lists the patterns, in the syntax of [path.Match], of the names of
+	// the tools exposed. All tools are allowed if empty.
+	Allow []string
+	// Deny lists the patterns of the names of the tools hidden, even if
+	// allowed.
+	Deny []string
+	// Tools transforms the tools, by their original names.
+	Tools map[string]ToolTransform
+}
+
+// ToolTransform changes how a tool is exposed.
+type ToolTransform struct {
+	// Name renames the tool, if not empty.
+	Name string
+	// Description replaces the description of the tool, if not empty.
+	Description string
+	// Arguments transforms the arguments of the tool, by their original
+	// names.
+	Arguments map[string]ArgumentTransform
+	// Pinned fixes the values of arguments, by their original names. Pinned
+	// arguments are hidden, and calls setting them are rejected.
+	Pinned map[string]string
+}
+
+// ArgumentTransform changes how an argument of a tool is exposed.
+type ArgumentTransform struct {
+	// Name renames the argument, if not empty.
+	Name string
+	// Description replaces the description of the argument, if not empty.
+	Description string
+	// Enum restricts the values of the argument, if not empty. Values outside
+	// the enumeration of the tool, if any, are left out.
+	Enum []string
+	// Required makes an optional argument required.
+	Required bool
+}
+
+// ErrToolNameConflict is reported by [ToolFilter.Validate] when tools would be
+// exposed under the same name.
+var ErrToolNameConflict = errors.New("mcp: conflicting tool names")
+
+// Validate reports the first malformed pattern of f, or the first tools of f
+// exposed under the same name.
+func (f *ToolFilter) Validate() error {
+	for _, pattern := range append(slices.Clone(f.Allow), f.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return err
+		}
+	}
+	exposed := make(map[string]string, len(f.Tools))
+	for _, original := range slices.Sorted(maps.Keys(f.Tools)) {
+		name := f.Tools[original].Name
+		if name == "" {
+			name = original
+		}
+		if other, ok := exposed[name]; ok {
+			return fmt.Errorf("%w: %q and %q are both exposed as %q", ErrToolNameConflict, other, original, name)
+		}
+		exposed[name] = original
+	}
+	return nil
+}
+
+// Allowed reports whether the tool named name is exposed.
+func (f *ToolFilter) Allowed(name string) bool {
+	match := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return (len(f.Allow) == 0 || match(f.Allow)) && !match(f.Deny)
+}
+
+// Apply returns spec as exposed by f, or false if the tool is hidden. Tools
+// keeping the name another tool is renamed to are hidden.
+func (f *ToolFilter) Apply(spec ToolSpec) (ToolSpec, bool) {
+	if !f.Allowed(spec.Name) {
+		return ToolSpec{}, false
+	}
+	t, ok := f.Tools[spec.Name]
+	if t.Name == "" && f.renamedTo(spec.Name) {
+		return ToolSpec{}, false
+	}
+	if !ok {
+		return spec, true
+	}
+	if t.Name != "" {
+		spec.Name = t.Name
+	}
+	if t.Description != "" {
+		spec.Description = t.Description
+	}
+
+	schema := ToolSchema{Type: spec.InputSchema.Type, Properties: make(map[string]ParamSchema)}
+	for name, param := range spec.InputSchema.Properties {
+		if _, pinned := t.Pinned[name]; pinned {
+			continue
+		}
+		arg := t.Arguments[name]
+		if arg.Description != "" {
+			param.Description = arg.Description
+		}
+		if len(arg.Enum) > 0 {
+			param.Enum = intersectEnum(param.Enum, arg.Enum)
+		}
+		schema.Properties[t.argumentName(name)] = param
+	}
+	for _, name := range spec.InputSchema.Required {
+		if _, pinned := t.Pinned[name]; !pinned {
+			schema.Required = append(schema.Required, t.argumentName(name))
+		}
+	}
+	var required []string
+	for name, arg := range t.Arguments {
+		if _, ok := spec.InputSchema.Properties[name]; ok && arg.Required && !slices.Contains(schema.Required, t.argumentName(name)) {
+			required = append(required, t.argumentName(name))
+		}
+	}
+	slices.Sort(required)
+	schema.Required = append(schema.Required, required...)
+	spec.InputSchema = schema
+	return spec, true
+}
+
+// renamedTo reports whether a tool is renamed to name.
+func (f *ToolFilter) renamedTo(name string) bool {
+	for original, t := range f.Tools {
+		if t.Name == name && original != name {
+			return true
+		}
+	}
+	return false
+}
+
+// original returns the original name of the tool exposed as name, or false if
+// no tool is exposed as name.
+func (f *ToolFilter) original(name string) (string, bool) {
+	for original, t := range f.Tools {
+		if t.Name == name {
+			return original, f.Allowed(original)
+		}
+	}
+	if t, ok := f.Tools[name]; ok && t.Name != "" && t.Name != name {
+		return "", false
+	}
+	return name, f.Allowed(name)
+}
+
+// Arguments returns the original arguments of a call with args of the tool
+// spec, as listed by its server, adding the pinned arguments. Arguments pinned,
+// out of their enumeration as exposed by [ToolFilter.Apply] or missing are
+// reported as invalid parameters.
+func (f *ToolFilter) Arguments(spec ToolSpec, args map[string]string) (map[string]string, *jsonrpc2.ErrorObject) {
+	t, ok := f.Tools[spec.Name]
+	if !ok {
+		return args, nil
+	}
+	originals := make(map[string]string, len(t.Arguments))
+	for name, arg := range t.Arguments {
+		if arg.Name != "" {
+			originals[arg.Name] = name
+		}
+	}
+
+	converted := make(map[string]string, len(args)+len(t.Pinned))
+	var invalid []string
+	for name, value := range args {
+		originalName, renamed := originals[name]
+		if !renamed {
+			originalName = name
+			if arg, ok := t.Arguments[name]; ok && arg.Name != "" && arg.Name != name {
+				invalid = append(invalid, name)
+				continue
+			}
+		}
+		if _, pinned := t.Pinned[originalName]; pinned {
+			invalid = append(invalid, name)
+			continue
+		}
+		if restricted := t.Arguments[originalName].Enum; len(restricted) > 0 {
+			enum := intersectEnum(spec.InputSchema.Properties[originalName].Enum, restricted)
+			if !slices.Contains(enum, value) {
+				invalid = append(invalid, name)
+				continue
+			}
+		}
+		converted[originalName] = value
+	}
+	if len(invalid) > 0 {
+		slices.Sort(invalid)
+		return nil, errInvalidArguments("invalid", invalid)
+	}
+
+	var missing []string
+	for name, arg := range t.Arguments {
+		if _, ok := converted[name]; arg.Required && !ok {
+			missing = append(missing, t.argumentName(name))
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return nil, errInvalidArguments("missing", missing)
+	}
+
+	for name, value := range t.Pinned {
+		converted[name] = value
+	}
+	return converted, nil
+}
+
+// intersectEnum returns the values of restricted within upstream, or
+// restricted if upstream is empty.
+func intersectEnum(upstream, restricted []string) []string {
+	if len(upstream) == 0 {
+		return restricted
+	}
+	enum := []string{}
+	for _, value := range restricted {
+		if slices.Contains(upstream, value) {
+			enum = append(enum, value)
+		}
+	}
+	return enum
+}
+
+// argumentName returns the exposed name of the argument named name.
+func (t ToolTransform) argumentName(name string) string {
+	if arg := t.Arguments[name]; arg.Name != "" {
+		return arg.Name
+	}
+	return name
+}
+
+// errInvalidArguments returns invalid parameters naming the arguments at
+// fault under key.
+func errInvalidArguments(key string, names []string) *jsonrpc2.ErrorObject {
+	obj := jsonrpc2.ErrObjInvalidParams
+	datajson, _ := json.Marshal(map[string][]string{key: names})
+	obj.Data = (*json.RawMessage)(&datajson)
+	return &obj
+}
+
+// FilteredTools is a CapToolsProvider exposing the tools of Provider through
+// Filter. Calls are checked against the tools last listed.
+type FilteredTools struct {
+	Provider CapToolsProvider
+	Filter   ToolFilter
+
+	mutex  sync.Mutex
+	listed map[string]ToolSpec // tools of the provider by name
+}
+
+// NewFilteredTools returns provider exposed through filter, or an error if a
+// pattern of filter is malformed.
+func NewFilteredTools(provider CapToolsProvider, filter ToolFilter) (*FilteredTools, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return &FilteredTools{Provider: provider, Filter: filter}, nil
+}
+
+func (f *FilteredTools) Tools_Started() *sync.Once {
+	return f.Provider.Tools_Started()
+}
+func (f *FilteredTools) Tools_Capability() *CapTools {
+	return f.Provider.Tools_Capability()
+}
+func (f *FilteredTools) Tools_ListChanged() chan struct{} {
+	return f.Provider.Tools_ListChanged()
+}
+
+// Tools_OnList lists the tools of the provider exposed by the filter, keeping
+// the pages of the provider.
+func (f *FilteredTools) Tools_OnList(cursor string) []ListToolsResonponse {
+	pages, _ := f.Tools_List(cursor)
+	return pages
+}
+
+// Tools_List is like Tools_OnList, answering the errors of a provider
+// implementing [CapToolsLister].
+func (f *FilteredTools) Tools_List(cursor string) ([]ListToolsResonponse, *jsonrpc2.ErrorObject) {
+	var pages []ListToolsResonponse
+	if lister, ok := f.Provider.(CapToolsLister); ok {
+		var erro *jsonrpc2.ErrorObject
+		if pages, erro = lister.Tools_List(cursor); erro != nil {
+			return nil, erro
+		}
+	} else {
+		pages = f.Provider.Tools_OnList(cursor)
+	}
+	f.mutex.Lock()
+	if f.listed == nil {
+		f.listed = make(map[string]ToolSpec)
+	}
+	for _, page := range pages {
+		for _, spec := range page.Tools {
+			f.listed[spec.Name] = spec
+		}
+	}
+	f.mutex.Unlock()
+
+	filtered := make([]ListToolsResonponse, 0, len(pages))
+	for _, page := range pages {
+		tools := []ToolSpec{}
+		for _, spec := range page.Tools {
+			if spec, ok := f.Filter.Apply(spec); ok {
+				tools = append(tools, spec)
+			}
+		}
+		filtered = append(filtered, ListToolsResonponse{Tools: tools, NextCursor: page.NextCursor})
+	}
+	return filtered, nil
+}
+
+func (f *FilteredTools) Tools_OnCall(name string, args map[string]string) (ToolCallResponse, *jsonrpc2.ErrorObject) {
+	original, ok := f.Filter.original(name)
+	if !ok {
+		return ToolCallResponse{}, errObj(jsonrpc2.ErrObjInvalidParams)
+	}
+	f.mutex.Lock()
+	spec, ok := f.listed[original]
+	f.mutex.Unlock()
+	if !ok {
+		// not listed yet, its arguments are only known to the filter
+		spec = ToolSpec{Name: original}
+	}
+	args, erro := f.Filter.Arguments(spec, args)
+	if erro != nil {
+		return ToolCallResponse{}, erro
+	}
+	return f.Provider.Tools_OnCall(original, args)
+}
+
+// ClientTools is a CapToolsProvider serving the tools of a remote server
+// through a client, to expose them again, for example through a
+// [FilteredTools]. Requests to the remote server are made in the session of
+// the client.
+type ClientTools struct {
+	client      *ClientState
+	started     sync.Once
+	listChanged chan struct{}
+}
+
+// NewClientTools returns the tools of the server of client, which must be
+// initialized.
+func NewClientTools(client *ClientState) *ClientTools {
+	t := &ClientTools{client: client, listChanged: make(chan struct{}, 1)}
+	client.OnToolsListChanged(func() {
+		// a pending signal already covers this change
+		select {
+		case t.listChanged <- struct{}{}:
+		default:
+		}
+	})
+	return t
+}
+
+func (t *ClientTools) Tools_Started() *sync.Once {
+	return &t.started
+}
+func (t *ClientTools) Tools_Capability() *CapTools {
+	caps := t.client.ctx.GetSession().GetServerCapabilities()
+	if caps == nil || caps.Tools == nil {
+		return nil
+	}
+	return &CapTools{ListChanged: caps.Tools.ListChanged}
+}
+func (t *ClientTools) Tools_ListChanged() chan struct{} {
+	return t.listChanged
+}
+
+// Tools_OnList returns the page of tools at cursor, or no tools if the server
+// fails to list them.
+func (t *ClientTools) Tools_OnList(cursor string) []ListToolsResonponse {
+	pages, erro := t.Tools_List(cursor)
+	if erro != nil {
+		return []ListToolsResonponse{{Tools: []ToolSpec{}}}
+	}
+	return pages
+}
+
+// Tools_List returns the page of tools at cursor, or the error of the server.
+func (t *ClientTools) Tools_List(cursor string) ([]ListToolsResonponse, *jsonrpc2.ErrorObject) {
+	pages, err := t.client.ToolsList(t.client.ctx, cursor)
+	if err != nil {
+		return nil, errObj(toErrorObject(err, t.client.logger()))
+	}
+	return pages, nil
+}
+
+func (t *ClientTools) Tools_OnCall(name string, args map[string]string) (ToolCallResponse, *jsonrpc2.ErrorObject) {
+	res, err := t.client.ToolCall(t.client.ctx, name, args)
+	if err != nil {
+		return ToolCallResponse{}, errObj(toErrorObject(err, t.client.logger()))
+	}
+	return res, nil
+}
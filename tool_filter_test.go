@@ -0,0 +1,249 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+// echoTools serves file tools answering with their arguments.
+type echoTools struct {
+	started     sync.Once
+	listChanged chan struct{}
+}
+
+func (p *echoTools) Tools_Started() *sync.Once        { return &p.started }
+func (p *echoTools) Tools_Capability() *CapTools      { return &CapTools{ListChanged: true} }
+func (p *echoTools) Tools_ListChanged() chan struct{} { return p.listChanged }
+func (p *echoTools) Tools_OnList(cursor string) []ListToolsResonponse {
+	schema := func(required ...string) ToolSchema {
+		return ToolSchema{
+			Type: "object",
+			Properties: map[string]ParamSchema{
+				"path":     {Type: "string", Description: "Path of the file"},
+				"encoding": {Type: "string", Description: "Encoding of the file"},
+				"root":     {Type: "string", Description: "Root directory"},
+			},
+			Required: required,
+		}
+	}
+	return []ListToolsResonponse{
+		{Tools: []ToolSpec{
+			{Name: "files_read", Description: "Read a file", InputSchema: schema("path", "root")},
+			{Name: "files_write", Description: "Write a file", InputSchema: schema("path", "root")},
+		}, NextCursor: "admin"},
+		{Tools: []ToolSpec{
+			{Name: "admin_reset", Description: "Reset everything", InputSchema: schema()},
+		}},
+	}
+}
+func (p *echoTools) Tools_OnCall(name string, args map[string]string) (ToolCallResponse, *jsonrpc2.ErrorObject) {
+	data, _ := json.Marshal(args)
+	return ToolCallResponse{Content: []Content{TextContent(name + " " + string(data))}}, nil
+}
+
+var testToolFilter = ToolFilter{
+	Allow: []string{"files_*", "admin_*"},
+	Deny:  []string{"admin_*"},
+	Tools: map[string]ToolTransform{
+		"files_read": {
+			Name:        "read_file",
+			Description: "Read a file of the project",
+			Arguments: map[string]ArgumentTransform{
+				"path":     {Name: "file"},
+				"encoding": {Description: "Text encoding", Enum: []string{"utf-8", "latin1"}, Required: true},
+			},
+			Pinned: map[string]string{"root": "/srv/project"},
+		},
+	},
+}
+
+func TestToolFilter(t *testing.T) {
+	f := testToolFilter
+	if err := f.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if err := (&ToolFilter{Deny: []string{"["}}).Validate(); err == nil {
+		t.Error("Expected malformed pattern to be reported")
+	}
+	for _, tools := range []map[string]ToolTransform{
+		{"files_read": {Name: "read"}, "files_cat": {Name: "read"}},
+		{"files_read": {Name: "files_write"}, "files_write": {Description: "Write a file"}},
+	} {
+		if err := (&ToolFilter{Tools: tools}).Validate(); !errors.Is(err, ErrToolNameConflict) {
+			t.Errorf("Expected the conflicting names of %v to be reported, got %v", tools, err)
+		}
+	}
+	if err := (&ToolFilter{Tools: map[string]ToolTransform{
+		"files_read": {Name: "files_write"}, "files_write": {Name: "files_read"},
+	}}).Validate(); err != nil {
+		t.Errorf("Expected swapped names to be valid, got %v", err)
+	}
+
+	t.Run("Apply", func(t *testing.T) {
+		tools := new(echoTools).Tools_OnList("")
+		spec, ok := f.Apply(tools[0].Tools[0])
+		if !ok {
+			t.Fatal("Expected files_read to be allowed")
+		}
+		want := ToolSpec{
+			Name:        "read_file",
+			Description: "Read a file of the project",
+			InputSchema: ToolSchema{
+				Type: "object",
+				Properties: map[string]ParamSchema{
+					"file":     {Type: "string", Description: "Path of the file"},
+					"encoding": {Type: "string", Description: "Text encoding", Enum: []string{"utf-8", "latin1"}},
+				},
+				Required: []string{"file", "encoding"},
+			},
+		}
+		if !reflect.DeepEqual(spec, want) {
+			t.Errorf("Expected %+v, got %+v", want, spec)
+		}
+		if spec, ok := f.Apply(tools[0].Tools[1]); !ok || !reflect.DeepEqual(spec, tools[0].Tools[1]) {
+			t.Errorf("Expected files_write unchanged, got %+v", spec)
+		}
+		if _, ok := f.Apply(tools[1].Tools[0]); ok {
+			t.Error("Expected admin_reset to be denied")
+		}
+		if _, ok := f.Apply(ToolSpec{Name: "read_file"}); ok {
+			t.Error("Expected a tool named like a renamed tool to be hidden")
+		}
+
+		upstream := tools[0].Tools[0]
+		upstream.InputSchema.Properties = map[string]ParamSchema{
+			"encoding": {Type: "string", Enum: []string{"ascii", "utf-8"}},
+		}
+		spec, _ = f.Apply(upstream)
+		if enum := spec.InputSchema.Properties["encoding"].Enum; !reflect.DeepEqual(enum, []string{"utf-8"}) {
+			t.Errorf("Expected the enumeration of the tool to be restricted, got %v", enum)
+		}
+	})
+
+	t.Run("Arguments", func(t *testing.T) {
+		spec := new(echoTools).Tools_OnList("")[0].Tools[0]
+		args, erro := f.Arguments(spec, map[string]string{"file": "a.txt", "encoding": "utf-8"})
+		want := map[string]string{"path": "a.txt", "encoding": "utf-8", "root": "/srv/project"}
+		if erro != nil || !reflect.DeepEqual(args, want) {
+			t.Errorf("Expected %v, got %v, %v", want, args, erro)
+		}
+
+		for _, tt := range []struct {
+			name string
+			args map[string]string
+			data string
+		}{
+			{"Pinned", map[string]string{"file": "a", "encoding": "utf-8", "root": "/"}, `{"invalid":["root"]}`},
+			{"OriginalName", map[string]string{"path": "a", "encoding": "utf-8"}, `{"invalid":["path"]}`},
+			{"Enum", map[string]string{"file": "a", "encoding": "ascii"}, `{"invalid":["encoding"]}`},
+			{"Required", map[string]string{"file": "a"}, `{"missing":["encoding"]}`},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				_, erro := f.Arguments(spec, tt.args)
+				if erro == nil || erro.Code != jsonrpc2.JSONRPC2ErrorInvalidParams || string(*erro.Data) != tt.data {
+					t.Errorf("Expected invalid params %s, got %v", tt.data, erro)
+				}
+			})
+		}
+
+		// the enumeration of the tool restricts the values accepted as listed
+		spec.InputSchema.Properties = map[string]ParamSchema{
+			"path":     {Type: "string"},
+			"encoding": {Type: "string", Enum: []string{"ascii", "utf-8"}},
+		}
+		if _, erro := f.Arguments(spec, map[string]string{"file": "a", "encoding": "utf-8"}); erro != nil {
+			t.Errorf("Expected utf-8 to be accepted, got %v", erro)
+		}
+		if _, erro := f.Arguments(spec, map[string]string{"file": "a", "encoding": "latin1"}); erro == nil {
+			t.Error("Expected latin1 to be rejected, as the tool does not accept it")
+		}
+	})
+}
+
+func TestFilteredTools(t *testing.T) {
+	// Upstream serves the echo tools, exposed again through a filter.
+	upstreamTools := &echoTools{listChanged: make(chan struct{})}
+	serverProvider := NewTestServerImpl()
+	upstream, err := SetupClientServer(&ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapToolsProvider:     upstreamTools,
+	}, &ClientImpl{})
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer upstream.Cleanup()
+	upstream.Init(t)
+
+	filtered, err := NewFilteredTools(NewClientTools(upstream.Client), testToolFilter)
+	if err != nil {
+		t.Fatalf("NewFilteredTools failed: %v", err)
+	}
+	ts, err := SetupClientServer(&ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapToolsProvider:     filtered,
+	}, &ClientImpl{})
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		var names []string
+		for tool, err := range ts.Client.AllTools(ts.Ctx) {
+			if err != nil {
+				t.Fatalf("AllTools failed: %v", err)
+			}
+			names = append(names, tool.Name)
+		}
+		if !reflect.DeepEqual(names, []string{"read_file", "files_write"}) {
+			t.Errorf("Unexpected tools: %v", names)
+		}
+	})
+
+	t.Run("Call", func(t *testing.T) {
+		response, err := ts.Client.ToolCall(ts.Ctx, "read_file", map[string]string{"file": "a.txt", "encoding": "latin1"})
+		want := `files_read {"encoding":"latin1","path":"a.txt","root":"/srv/project"}`
+		if err != nil || response.Content[0].Text != want {
+			t.Errorf("Expected %s, got %+v, %v", want, response, err)
+		}
+
+		for _, name := range []string{"files_read", "admin_reset", "unknown"} {
+			_, err := ts.Client.ToolCall(ts.Ctx, name, nil)
+			if rpcErr, ok := err.(*jsonrpc2.ErrorObject); !ok || rpcErr.Code != jsonrpc2.JSONRPC2ErrorInvalidParams {
+				t.Errorf("Expected %s to be hidden, got %v", name, err)
+			}
+		}
+		_, err = ts.Client.ToolCall(ts.Ctx, "read_file", map[string]string{"file": "a.txt"})
+		if rpcErr, ok := err.(*jsonrpc2.ErrorObject); !ok || rpcErr.Code != jsonrpc2.JSONRPC2ErrorInvalidParams {
+			t.Errorf("Expected missing argument to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("ListChanged", func(t *testing.T) {
+		received := make(chan struct{}, 1)
+		ts.Client.OnToolsListChanged(func() { received <- struct{}{} })
+		upstreamTools.listChanged <- struct{}{}
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for the list change of the upstream")
+		}
+	})
+	t.Run("ListFailure", func(t *testing.T) {
+		upstream.Cleanup()
+		_, err := ts.Client.ToolsList(ts.Ctx, "")
+		if rpcErr, ok := err.(*jsonrpc2.ErrorObject); !ok || rpcErr.Code != jsonrpc2.JSONRPC2ErrorInternalError {
+			t.Errorf("Expected the failure of the upstream, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+)
+
+const (
+	headerSessionID       = "Mcp-Session-Id"
+	headerProtocolVersion = "MCP-Protocol-Version"
+)
+
+// StreamableHTTP connects to a server over the streamable HTTP transport.
+type StreamableHTTP struct {
+	URL string
+	// Header is added to the requests made to the server.
+	Header http.Header
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Dial returns a connection to the server, which is only contacted when the
+// first message is sent. Each message is posted to the server in the order
+// sent, and the server answers with JSON or with a stream of events. A request
+// the server fails with an HTTP status is answered with an error response,
+// except a 404 which ends the connection as the session is gone. Once the
+// session is initialized, a stream of the requests and notifications of the
+// server is opened, if the server offers one. Closing the connection ends the
+// session.
+func (t *StreamableHTTP) Dial(ctx context.Context) (io.ReadWriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c := &httpConn{conn: newConn(), transport: t, client: t.Client}
+	if c.client == nil {
+		c.client = http.DefaultClient
+	}
+	go c.postQueued(c.post)
+	return c, nil
+}
+
+type httpConn struct {
+	*conn
+	transport *StreamableHTTP
+	client    *http.Client
+
+	mutex           sync.Mutex
+	sessionID       string
+	protocolVersion string
+	closeOnce       sync.Once
+}
+
+func (c *httpConn) request(ctx context.Context, method string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.transport.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	setHeader(req, c.transport.Header)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.sessionID != "" {
+		req.Header.Set(headerSessionID, c.sessionID)
+	}
+	if c.protocolVersion != "" {
+		req.Header.Set(headerProtocolVersion, c.protocolVersion)
+	}
+	return req, nil
+}
+
+// post sends message, and receives the response of the server. wrote is called
+// once the message is written, or could not be.
+func (c *httpConn) post(message []byte, wrote func()) {
+	defer wrote()
+	req, err := c.request(c.ctx, http.MethodPost, bytes.NewReader(message))
+	if err != nil {
+		c.fail(err)
+		return
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) { wrote() },
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	res, err := c.client.Do(req)
+	if err != nil {
+		c.fail(err)
+		return
+	}
+	defer res.Body.Close()
+	if !c.checkPosted(message, res) {
+		return
+	}
+	if id := res.Header.Get(headerSessionID); id != "" {
+		c.mutex.Lock()
+		c.sessionID = id
+		c.mutex.Unlock()
+	}
+
+	if res.StatusCode == http.StatusAccepted {
+		var notification struct{ Method string }
+		if json.Unmarshal(message, &notification) == nil && notification.Method == "notifications/initialized" {
+			go c.listen()
+		}
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		err := readEvents(res.Body, func(event, data string) {
+			if event == "" || event == "message" {
+				c.deliver([]byte(data))
+			}
+		})
+		if err != nil {
+			c.fail(err)
+		}
+		return
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.fail(err)
+		return
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		c.deliver(data)
+	}
+}
+
+// listen receives the messages sent by the server outside of responses, until
+// the server ends the stream. Servers not offering the stream answer 405.
+func (c *httpConn) listen() {
+	req, err := c.request(c.ctx, http.MethodGet, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	if checkStatus(res) != nil {
+		return
+	}
+	readEvents(res.Body, func(event, data string) {
+		if event == "" || event == "message" {
+			c.deliver([]byte(data))
+		}
+	})
+}
+
+// deliver receives message, keeping the protocol version negotiated by the
+// initialize response, which is sent with the later requests.
+func (c *httpConn) deliver(message []byte) {
+	c.mutex.Lock()
+	if c.protocolVersion == "" {
+		var response struct {
+			Result struct {
+				ProtocolVersion string `json:"protocolVersion"`
+			} `json:"result"`
+		}
+		if json.Unmarshal(message, &response) == nil {
+			c.protocolVersion = response.Result.ProtocolVersion
+		}
+	}
+	c.mutex.Unlock()
+	c.receive(message)
+}
+
+// Close ends the session on the server, and the connection.
+func (c *httpConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.conn.Close()
+		c.mutex.Lock()
+		sessionID := c.sessionID
+		c.mutex.Unlock()
+		if sessionID == "" {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), CloseTimeout)
+		defer cancel()
+		req, err := c.request(ctx, http.MethodDelete, nil)
+		if err != nil {
+			return
+		}
+		if res, err := c.client.Do(req); err == nil {
+			res.Body.Close()
+		}
+	})
+	return nil
+}
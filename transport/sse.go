@@ -0,0 +1,124 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+var (
+	ErrNoEndpoint     = errors.New("transport: no endpoint event")
+	ErrEndpointOrigin = errors.New("transport: endpoint of another origin")
+)
+
+// SSE connects to a server over the HTTP with SSE transport, which preceded
+// the streamable HTTP transport.
+type SSE struct {
+	URL string
+	// Header is added to the requests made to the server.
+	Header http.Header
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Dial opens the stream of events of the server, and waits for the endpoint
+// to which messages are posted, which must have the scheme and host of the URL
+// as the messages carry the headers. Messages are posted in the order sent, and
+// a request the server fails with an HTTP status is answered with an error
+// response, except a 404 which ends the connection. The messages of the server
+// are received over the stream, and the connection ends with it.
+func (t *SSE) Dial(ctx context.Context) (io.ReadWriteCloser, error) {
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	base, err := url.Parse(t.URL)
+	if err != nil {
+		return nil, err
+	}
+	c := newConn()
+	stop := context.AfterFunc(ctx, func() { c.Close() })
+	defer stop()
+
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, t.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	setHeader(req, t.Header)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := client.Do(req)
+	if err != nil {
+		c.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	if err := checkStatus(res); err != nil {
+		res.Body.Close()
+		c.Close()
+		return nil, err
+	}
+
+	endpoints := make(chan *url.URL, 1)
+	go func() {
+		defer res.Body.Close()
+		var endpoint *url.URL
+		err := readEvents(res.Body, func(event, data string) {
+			switch {
+			case event == "endpoint" && endpoint == nil:
+				if endpoint, _ = base.Parse(data); endpoint != nil {
+					endpoints <- endpoint
+				}
+			case event == "" || event == "message":
+				c.receive([]byte(data))
+			}
+		})
+		if err == nil {
+			err = io.EOF
+		}
+		c.fail(err)
+		close(endpoints)
+	}()
+
+	endpoint, ok := <-endpoints
+	if !ok {
+		c.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, ErrNoEndpoint
+	}
+	if endpoint.Scheme != base.Scheme || endpoint.Host != base.Host {
+		c.Close()
+		return nil, ErrEndpointOrigin
+	}
+	go c.postQueued(func(message []byte, wrote func()) {
+		t.post(c, client, endpoint, message, wrote)
+	})
+	return c, nil
+}
+
+// post sends message to endpoint, whose response is received over the stream.
+// wrote is called once the server answered, or could not be reached.
+func (t *SSE) post(c *conn, client *http.Client, endpoint *url.URL, message []byte, wrote func()) {
+	defer wrote()
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, endpoint.String(), bytes.NewReader(message))
+	if err != nil {
+		c.fail(err)
+		return
+	}
+	setHeader(req, t.Header)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		c.fail(err)
+		return
+	}
+	defer res.Body.Close()
+	wrote()
+	c.checkPosted(message, res)
+}
@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Stdio runs a server as a subprocess, exchanging messages over its standard
+// input and output.
+type Stdio struct {
+	Command string
+	Args    []string
+	// Env is the environment of the subprocess, defaults to the environment
+	// of the process.
+	Env []string
+	Dir string
+	// Stderr receives the standard error of the subprocess, discarded if nil.
+	Stderr io.Writer
+}
+
+// Dial starts the subprocess. Closing the connection closes its standard
+// input, and kills it unless it exits within CloseTimeout.
+func (t *Stdio) Dial(ctx context.Context) (io.ReadWriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(t.Command, t.Args...)
+	cmd.Env = t.Env
+	cmd.Dir = t.Dir
+	cmd.Stderr = t.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	// don't wait for subprocesses keeping the output open
+	cmd.WaitDelay = CloseTimeout
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	c := &stdio{cmd: cmd, stdin: stdin, stdout: reader, exited: make(chan struct{})}
+	go func() {
+		err := cmd.Wait()
+		if err == nil {
+			err = io.EOF
+		}
+		writer.CloseWithError(err)
+		close(c.exited)
+	}()
+	return c, nil
+}
+
+type stdio struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	stdout    *io.PipeReader
+	exited    chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *stdio) Read(p []byte) (int, error) {
+	return c.stdout.Read(p)
+}
+
+func (c *stdio) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+func (c *stdio) Close() error {
+	c.closeOnce.Do(func() {
+		c.stdin.Close()
+		c.stdout.Close()
+		select {
+		case <-c.exited:
+		case <-time.After(CloseTimeout):
+			c.cmd.Process.Kill()
+			<-c.exited
+		}
+	})
+	return nil
+}
@@ -0,0 +1,227 @@
+// Package transport connects clients to MCP servers over the transports of the
+// specification: the standard input and output of a subprocess, the streamable
+// HTTP transport, and the HTTP with SSE transport of earlier versions.
+//
+// Connections are io.ReadWriteCloser exchanging newline delimited JSON
+// messages, as taken by mcp.NewClient.
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
+
+// CloseTimeout bounds the time taken by closing a connection to end the
+// session with the server.
+var CloseTimeout = 5 * time.Second
+
+var (
+	ErrClosed     = errors.New("transport: connection closed")
+	ErrHTTPStatus = errors.New("transport: unexpected HTTP status")
+)
+
+// Dialer opens connections to a server.
+type Dialer interface {
+	Dial(ctx context.Context) (io.ReadWriteCloser, error)
+}
+
+// conn is a connection exchanging messages over HTTP. Lines written are sent
+// as messages, and messages received are read as lines.
+type conn struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	// queue holds the lines written, posted in order by postQueued
+	queue chan []byte
+
+	reader    *io.PipeReader
+	writer    *io.PipeWriter
+	readMutex sync.Mutex
+	pending   []byte
+	sendMutex sync.Mutex
+}
+
+func newConn() *conn {
+	ctx, cancel := context.WithCancel(context.Background())
+	reader, writer := io.Pipe()
+	return &conn{ctx: ctx, cancel: cancel, reader: reader, writer: writer, queue: make(chan []byte)}
+}
+
+func (c *conn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+func (c *conn) Write(p []byte) (int, error) {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+	if c.ctx.Err() != nil {
+		return 0, ErrClosed
+	}
+	c.pending = append(c.pending, p...)
+	for {
+		i := bytes.IndexByte(c.pending, '\n')
+		if i < 0 {
+			break
+		}
+		line := bytes.TrimSpace(c.pending[:i])
+		c.pending = c.pending[i+1:]
+		if len(line) > 0 {
+			select {
+			case c.queue <- bytes.Clone(line):
+			case <-c.ctx.Done():
+				return len(p), ErrClosed
+			}
+		}
+	}
+	return len(p), nil
+}
+
+// postQueued posts the messages written with post, in order. post calls wrote
+// once the message is written, or could not be. The next message is posted
+// once a request is written, without waiting for its response which may stream
+// for long, and once post returns for any other message.
+func (c *conn) postQueued(post func(message []byte, wrote func())) {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case message := <-c.queue:
+			if requestID(message) == nil {
+				post(message, func() {})
+				continue
+			}
+			wrote := make(chan struct{})
+			go post(message, sync.OnceFunc(func() { close(wrote) }))
+			<-wrote
+		}
+	}
+}
+
+// checkPosted reports whether res, the response to posting message, is a
+// success. A request failed with another status is answered with an error
+// response, except a 404 which ends the connection as the session is gone.
+func (c *conn) checkPosted(message []byte, res *http.Response) bool {
+	err := checkStatus(res)
+	switch {
+	case err == nil:
+		return true
+	case res.StatusCode == http.StatusNotFound:
+		c.fail(err)
+	default:
+		c.reject(message, err)
+	}
+	return false
+}
+
+// reject answers message with an internal error carrying err, if it is a
+// request.
+func (c *conn) reject(message []byte, err error) {
+	id := requestID(message)
+	if id == nil {
+		return
+	}
+	erro := jsonrpc2.ErrObjInternalError
+	erro.Message = err.Error()
+	response, _ := json.Marshal(struct {
+		Version string                `json:"jsonrpc"`
+		ID      json.RawMessage       `json:"id"`
+		Error   *jsonrpc2.ErrorObject `json:"error"`
+	}{jsonrpc2.JSONRPC2Version, id, &erro})
+	c.receive(response)
+}
+
+// requestID returns the id of message, or nil if it is not a request.
+func requestID(message []byte) json.RawMessage {
+	var request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if json.Unmarshal(message, &request) != nil || request.Method == "" || string(request.ID) == "null" {
+		return nil
+	}
+	return request.ID
+}
+
+// receive makes message read as a line.
+func (c *conn) receive(message []byte) {
+	var line bytes.Buffer
+	if err := json.Compact(&line, message); err != nil {
+		// let the peer report the malformed message
+		line.Reset()
+		line.Write(bytes.ReplaceAll(message, []byte("\n"), []byte(" ")))
+	}
+	line.WriteByte('\n')
+	c.readMutex.Lock()
+	defer c.readMutex.Unlock()
+	c.writer.Write(line.Bytes())
+}
+
+// fail ends the connection, reads returning err.
+func (c *conn) fail(err error) {
+	if c.ctx.Err() == nil {
+		c.writer.CloseWithError(err)
+	}
+}
+
+func (c *conn) Close() error {
+	c.cancel()
+	c.reader.Close()
+	c.writer.Close()
+	return nil
+}
+
+// setHeader adds the headers of header to req.
+func setHeader(req *http.Request, header http.Header) {
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+}
+
+// checkStatus returns an error wrapping ErrHTTPStatus if res is not a
+// success, with the start of its body.
+func checkStatus(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 256))
+	return fmt.Errorf("%w: %s %s", ErrHTTPStatus, res.Status, strings.TrimSpace(string(body)))
+}
+
+// readEvents reads the server-sent events of r, calling handle with the name
+// and data of each event.
+func readEvents(r io.Reader, handle func(event, data string)) error {
+	scanner := bufio.NewScanner(r)
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			if len(data) > 0 {
+				handle(event, strings.Join(data, "\n"))
+			}
+			event, data = "", nil
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,347 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess echoes its standard input when run by TestStdio.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("TRANSPORT_HELPER_PROCESS") != "1" {
+		return
+	}
+	io.Copy(os.Stdout, os.Stdin)
+	os.Exit(0)
+}
+
+// exchange writes the lines of messages to conn, and returns the lines read
+// back, n at most.
+func exchange(t *testing.T, conn io.ReadWriteCloser, n int, messages ...string) []string {
+	t.Helper()
+	for _, message := range messages {
+		if _, err := io.WriteString(conn, message+"\n"); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(conn)
+		for i := 0; i < n && scanner.Scan(); i++ {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	var read []string
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return read
+			}
+			read = append(read, line)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout reading, got %v", read)
+		}
+	}
+}
+
+func TestStdio(t *testing.T) {
+	dialer := &Stdio{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=TestHelperProcess"},
+		Env:     append(os.Environ(), "TRANSPORT_HELPER_PROCESS=1", "GORACE=atexit_sleep_ms=0"),
+	}
+	conn, err := dialer.Dial(context.Background())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	got := exchange(t, conn, 2, `{"id":1}`, `{"id":2}`)
+	if strings.Join(got, " ") != `{"id":1} {"id":2}` {
+		t.Errorf("Unexpected lines: %v", got)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		conn.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the subprocess to exit once its input is closed")
+	}
+
+	if _, err := (&Stdio{Command: "/nonexistent"}).Dial(context.Background()); err == nil {
+		t.Error("Expected missing command to fail")
+	}
+}
+
+// rpcMessage is a JSON-RPC message received by the test servers.
+type rpcMessage struct {
+	ID     any    `json:"id"`
+	Method string `json:"method"`
+}
+
+func result(id any, v string) string {
+	data, _ := json.Marshal(id)
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, data, v)
+}
+
+func TestStreamableHTTP(t *testing.T) {
+	var mutex sync.Mutex
+	var headers []string
+	var deleted bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		headers = append(headers, r.Method+" "+r.Header.Get("Mcp-Session-Id")+" "+r.Header.Get("MCP-Protocol-Version")+" "+r.Header.Get("Authorization"))
+		mutex.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\n")
+			fmt.Fprint(w, "data: \"method\":\"notifications/hello\"}\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		case http.MethodDelete:
+			mutex.Lock()
+			deleted = true
+			mutex.Unlock()
+			return
+		}
+
+		var message rpcMessage
+		json.NewDecoder(r.Body).Decode(&message)
+		switch {
+		case message.ID == nil:
+			w.WriteHeader(http.StatusAccepted)
+		case message.Method == "initialize":
+			w.Header().Set("Mcp-Session-Id", "session-1")
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, result(message.ID, `{"protocolVersion":"2025-06-18"}`))
+		case message.Method == "fail":
+			http.Error(w, "no such session", http.StatusNotFound)
+		default:
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			fmt.Fprintf(w, "data: %s\n\n", result(message.ID, `{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+
+	dialer := &StreamableHTTP{URL: server.URL, Header: http.Header{"Authorization": {"Bearer token"}}}
+	conn, err := dialer.Dial(context.Background())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	reader := bufio.NewScanner(conn)
+	read := func() string {
+		t.Helper()
+		lines := make(chan string, 1)
+		go func() {
+			reader.Scan()
+			lines <- reader.Text()
+		}()
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout reading")
+			return ""
+		}
+	}
+
+	io.WriteString(conn, `{"jsonrpc":"2.0","id":1,"method":"initialize"}`+"\n")
+	if got, want := read(), result(1, `{"protocolVersion":"2025-06-18"}`); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	io.WriteString(conn, `{"jsonrpc":"2.0","method":"notifications/initialized"}`+"\n")
+	if got := read(); got != `{"jsonrpc":"2.0","method":"notifications/hello"}` {
+		t.Errorf("Expected the notification of the server stream, got %s", got)
+	}
+	io.WriteString(conn, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`+"\n")
+	if got := read(); got != `{"jsonrpc":"2.0","method":"notifications/progress"}` {
+		t.Errorf("Expected the notification of the response stream, got %s", got)
+	}
+	if got, want := read(), result(2, `{"ok":true}`); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+
+	conn.Close()
+	mutex.Lock()
+	defer mutex.Unlock()
+	want := []string{
+		"POST   Bearer token",
+		"POST session-1 2025-06-18 Bearer token",
+		"GET session-1 2025-06-18 Bearer token",
+		"POST session-1 2025-06-18 Bearer token",
+		"DELETE session-1 2025-06-18 Bearer token",
+	}
+	if strings.Join(headers, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected requests:\n%s", strings.Join(headers, "\n"))
+	}
+	if !deleted {
+		t.Error("Expected the session to be deleted")
+	}
+}
+
+func TestStreamableHTTPStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such session", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	conn, err := (&StreamableHTTP{URL: server.URL}).Dial(context.Background())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, `{"jsonrpc":"2.0","id":1,"method":"ping"}`+"\n")
+	if _, err := io.ReadAll(conn); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected the connection to fail with the status, got %v", err)
+	}
+}
+
+func TestStreamableHTTPOrder(t *testing.T) {
+	var mutex sync.Mutex
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message rpcMessage
+		json.NewDecoder(r.Body).Decode(&message)
+		if message.Method == "notifications/slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		mutex.Lock()
+		methods = append(methods, message.Method)
+		mutex.Unlock()
+		switch {
+		case message.ID == nil:
+			w.WriteHeader(http.StatusAccepted)
+		case message.Method == "fail":
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, result(message.ID, `{}`))
+		}
+	}))
+	defer server.Close()
+
+	conn, err := (&StreamableHTTP{URL: server.URL}).Dial(context.Background())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	got := exchange(t, conn, 2,
+		`{"jsonrpc":"2.0","method":"notifications/slow"}`,
+		`{"jsonrpc":"2.0","method":"notifications/fast"}`,
+		`{"jsonrpc":"2.0","id":1,"method":"fail"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+	)
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 responses, got %v", got)
+	}
+	// the responses to the requests come in any order
+	slices.Sort(got)
+	var response struct {
+		ID    int
+		Error struct {
+			Code    int
+			Message string
+		}
+	}
+	json.Unmarshal([]byte(got[0]), &response)
+	if response.ID != 1 || response.Error.Code != -32603 || !strings.Contains(response.Error.Message, "503") {
+		t.Errorf("Expected an error response to the failed request, got %s", got[0])
+	}
+	if got[1] != result(2, `{}`) {
+		t.Errorf("Expected the connection to stay usable, got %s", got[1])
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if strings.Join(methods[:2], " ") != "notifications/slow notifications/fast" {
+		t.Errorf("Expected the notifications in order, got %v", methods)
+	}
+}
+
+func TestSSE(t *testing.T) {
+	messages := make(chan string, 10)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sse", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": connected\n\nevent: endpoint\ndata: /messages?session=1\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case message := <-messages:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", message)
+				w.(http.Flusher).Flush()
+			}
+		}
+	})
+	mux.HandleFunc("POST /messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("session") != "1" {
+			http.Error(w, "no such session", http.StatusNotFound)
+			return
+		}
+		var message rpcMessage
+		json.NewDecoder(r.Body).Decode(&message)
+		if message.Method == "fail" {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		messages <- result(message.ID, `"`+message.Method+`"`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	conn, err := (&SSE{URL: server.URL + "/sse"}).Dial(context.Background())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	got := exchange(t, conn, 3,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"fail"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+	)
+	if len(got) != 3 || got[0] != result(1, `"initialize"`) || got[2] != result(3, `"ping"`) {
+		t.Errorf("Expected the messages posted in order, got %v", got)
+	} else if !strings.HasPrefix(got[1], `{"jsonrpc":"2.0","id":2,"error":{"code":-32603`) {
+		t.Errorf("Expected an error response to the failed request, got %s", got[1])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := (&SSE{URL: server.URL + "/sse"}).Dial(ctx); err == nil {
+		t.Error("Expected dial to fail with a done context")
+	}
+	if _, err := (&SSE{URL: server.URL + "/messages"}).Dial(context.Background()); err == nil {
+		t.Error("Expected dial to fail without a stream")
+	}
+
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: endpoint\ndata: %s/messages?session=1\n\n", server.URL)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer foreign.Close()
+	if _, err := (&SSE{URL: foreign.URL}).Dial(context.Background()); err != ErrEndpointOrigin {
+		t.Errorf("Expected dial to fail with an endpoint of another origin, got %v", err)
+	}
+}
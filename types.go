@@ -1,6 +1,11 @@
 package mcp
 
-import "github.com/vibeus/mcp/jsonrpc2"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/vibeus/mcp/jsonrpc2"
+)
 
 var (
 	kMethodPing                   = "ping"
@@ -15,21 +20,39 @@ var (
 	kMethodResourcesList          = "resources/list"
 	kMethodResourcesRead          = "resources/read"
 	kMethodResourcesTemplatesList = "resources/templates/list"
+	kMethodResourcesSubscribe     = "resources/subscribe"
+	kMethodResourcesUnsubscribe   = "resources/unsubscribe"
 	kMethodResourcesListChanged   = "notifications/resources/list_changed"
+	kMethodResourcesUpdated       = "notifications/resources/updated"
 	kMethodToolsList              = "tools/list"
 	kMethodToolsCall              = "tools/call"
 	kMethodToolsListChanged       = "notifications/tools/list_changed"
+	kMethodLoggingMessage         = "notifications/message"
+	kMethodProgress               = "notifications/progress"
 
 	LatestMCPVersion = "2025-03-26"
 )
 var (
 	JSONRPC2ResourceNotFound = -32002
+	JSONRPC2SamplingRejected = -1
 )
 var (
 	kErrObjResourceNotFound = jsonrpc2.ErrorObject{
 		Code:    JSONRPC2ResourceNotFound,
 		Message: "Resource not found",
 	}
+	kErrObjSamplingRejected = jsonrpc2.ErrorObject{
+		Code:    JSONRPC2SamplingRejected,
+		Message: "User rejected sampling request",
+	}
+	kErrObjSamplingRateLimited = jsonrpc2.ErrorObject{
+		Code:    JSONRPC2SamplingRejected,
+		Message: "Sampling rate limit exceeded",
+	}
+	kErrObjSamplingTooManyTokens = jsonrpc2.ErrorObject{
+		Code:    JSONRPC2SamplingRejected,
+		Message: "Sampling request exceeds the maximum tokens",
+	}
 )
 
 type CapRoots struct {
@@ -89,39 +112,90 @@ type ServerInitializeInfo struct {
 	Capabilities    ServerCapabilities `json:"capabilities"`
 }
 
-type SamplingMessageContent struct {
-	Type     string `json:"type"`
-	Text     string `json:"text"`
-	Data     string `json:"data"`
-	MimeType string `json:"mimeType"`
-}
-
 type SamplingMessageItem struct {
-	Role    string                 `json:"role"`
-	Content SamplingMessageContent `json:"content"`
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
 }
 
 type SamplingMessageModelHint struct {
+	// substring of a model name, such as "claude-3-5-sonnet" or "sonnet"
 	Name string `json:"name"`
 }
 
+// SamplingMessageModelPreference expresses the preferences of a server for
+// the model used to sample a message. Priorities range from 0 to 1, and unset
+// priorities do not matter to the server.
 type SamplingMessageModelPreference struct {
-	Hints                []SamplingMessageModelHint `json:"hints"`
-	CostPriority         float32                    `json:"costPriority"`
-	CpeedPriority        float32                    `json:"speedPriority"`
-	IntelligencePriority float32                    `json:"intelligencePriority"`
+	// models to consider, in order of preference
+	Hints                []SamplingMessageModelHint `json:"hints,omitempty"`
+	CostPriority         *float64                   `json:"costPriority,omitempty"`
+	SpeedPriority        *float64                   `json:"speedPriority,omitempty"`
+	IntelligencePriority *float64                   `json:"intelligencePriority,omitempty"`
 }
 
+const (
+	IncludeContextNone       = "none"
+	IncludeContextThisServer = "thisServer"
+	IncludeContextAllServers = "allServers"
+)
+
 type SamplingMessage struct {
-	Messages        []SamplingMessageItem          `json:"messages"`
-	ModelPreference SamplingMessageModelPreference `json:"modelPreference"`
-	SystemPrompt    string                         `json:"systemPrompt"`
-	MaxTokens       int64                          `json:"maxTokens"`
+	Messages         []SamplingMessageItem           `json:"messages"`
+	ModelPreferences *SamplingMessageModelPreference `json:"modelPreferences,omitempty"`
+	SystemPrompt     string                          `json:"systemPrompt,omitempty"`
+	// context of MCP servers to include in the prompt, IncludeContextNone by
+	// default
+	IncludeContext string         `json:"includeContext,omitempty"`
+	Temperature    *float64       `json:"temperature,omitempty"`
+	MaxTokens      int64          `json:"maxTokens"`
+	StopSequences  []string       `json:"stopSequences,omitempty"`
+	Metadata       map[string]any `json:"metadata,omitempty"` // provider specific
+}
+
+// Validate reports the content of messages that cannot be sampled, wrapping
+// [ErrInvalidContent].
+func (m SamplingMessage) Validate() error {
+	for _, item := range m.Messages {
+		if err := item.Content.Validate(); err != nil {
+			return err
+		}
+		switch item.Content.Type {
+		case ContentTypeText, ContentTypeImage, ContentTypeAudio:
+		default:
+			return fmt.Errorf("%w: %s content cannot be sampled", ErrInvalidContent, item.Content.Type)
+		}
+	}
+	return nil
 }
 
+const (
+	StopReasonEndTurn      = "endTurn"
+	StopReasonStopSequence = "stopSequence"
+	StopReasonMaxTokens    = "maxTokens"
+)
+
 type SamplingResponse struct {
-	Role       string                 `json:"role"`
-	Content    SamplingMessageContent `json:"content"`
-	Model      string                 `json:"model"`
-	StopReason string                 `json:"stopReason"`
+	Role       string  `json:"role"`
+	Content    Content `json:"content"`
+	Model      string  `json:"model"`
+	StopReason string  `json:"stopReason,omitempty"`
+}
+
+type ResourceUpdatedNotification struct {
+	URI string `json:"uri"`
+}
+
+type LogMessageNotification struct {
+	// one of debug, info, notice, warning, error, critical, alert, emergency
+	Level  string          `json:"level"`
+	Logger string          `json:"logger,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type ProgressNotification struct {
+	// the token given in the _meta of the request the progress is reported for
+	ProgressToken jsonrpc2.ID `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
 }
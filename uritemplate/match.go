@@ -0,0 +1,188 @@
+package uritemplate
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// reservedClass matches the reserved characters inside a regexp character
+// class. They are percent-encoded by all but the reserved and fragment
+// expansions, except for the commas separating the items of lists and the
+// equal signs separating the keys and values of associative arrays.
+const reservedClass = `:/?#\[\]@!$&'()*+;`
+
+// compile builds the regexp used to match URIs against the template.
+//
+// Expressions match empty values, and the expressions of all operators but
+// the simple and reserved ones are optional, as undefined variables expand to
+// nothing. Variables are matched lazily, so that optional expressions take
+// what they can. Path-style parameter and query expressions accept their
+// parameters in any order, mixed with unknown ones.
+func (t *Template) compile() error {
+	var b strings.Builder
+	b.WriteByte('^')
+	for _, p := range t.parts {
+		if !p.isExpression() {
+			b.WriteString(regexp.QuoteMeta(p.literal))
+			continue
+		}
+		switch p.opChar {
+		case '?', '&':
+			b.WriteString(`(?:` + regexp.QuoteMeta(p.op.first) + `([^#]*))?`)
+			t.captures = append(t.captures, capture{params: p.vars, sep: "&"})
+		case ';':
+			b.WriteString(`((?:;[^;/?#]*)*)`)
+			t.captures = append(t.captures, capture{params: p.vars, sep: ";"})
+		case '#':
+			b.WriteString(`(?:#`)
+			t.compileVars(&b, p, `.*?`)
+			b.WriteString(`)?`)
+		case '+':
+			t.compileVars(&b, p, `.*?`)
+		case '.':
+			b.WriteString(`(?:\.`)
+			t.compileVars(&b, p, `[^.`+reservedClass+`]*?`)
+			b.WriteString(`)?`)
+		case '/':
+			b.WriteString(`(?:/`)
+			t.compileVars(&b, p, `[^`+reservedClass+`]*?`)
+			b.WriteString(`)?`)
+		default:
+			t.compileVars(&b, p, `[^`+reservedClass+`]*?`)
+		}
+	}
+	b.WriteByte('$')
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	t.re = re
+	return nil
+}
+
+// compileVars captures each variable of p with item, separated by the
+// separator of its operator. An exploded variable captures a list of items.
+func (t *Template) compileVars(b *strings.Builder, p part, item string) {
+	sep := regexp.QuoteMeta(p.op.sep)
+	for i, v := range p.vars {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		if v.explode {
+			b.WriteString(`(` + item + `(?:` + sep + item + `)*)`)
+		} else {
+			b.WriteString(`(` + item + `)`)
+		}
+		t.captures = append(t.captures, capture{name: v.name, sep: p.op.sep, explode: v.explode, reserved: p.op.reserved})
+	}
+}
+
+// Match matches uri against the template and returns the decoded values of
+// its variables. The items of a list, as captured by an exploded variable or a
+// repeated parameter, are joined with commas, as are the keys and values of an
+// associative array, like in the expansion of a variable not exploded.
+// Associative arrays exploded by reserved or fragment expansions cannot be
+// told from lists, and are returned as expanded. Variables absent from uri are
+// absent from the result.
+func (t *Template) Match(uri string) (map[string]string, bool) {
+	m := t.re.FindStringSubmatchIndex(uri)
+	if m == nil {
+		return nil, false
+	}
+	vars := make(map[string]string)
+	for i, c := range t.captures {
+		start, end := m[2*i+2], m[2*i+3]
+		if start < 0 {
+			continue
+		}
+		raw := uri[start:end]
+		if c.params != nil {
+			if !matchParams(raw, c.sep, c.params, vars) {
+				return nil, false
+			}
+			continue
+		}
+		items := []string{raw}
+		if c.explode && !c.reserved {
+			items = strings.Split(raw, c.sep)
+			if isMap(items) {
+				items = splitPairs(items)
+			}
+		}
+		for j, item := range items {
+			decoded, err := url.PathUnescape(item)
+			if err != nil {
+				return nil, false
+			}
+			items[j] = decoded
+		}
+		vars[c.name] = strings.Join(items, ",")
+	}
+	return vars, true
+}
+
+// isMap reports whether the items of an exploded variable are the key=value
+// pairs of an associative array. Outside of reserved expansions, '=' is only
+// left unencoded between keys and values.
+func isMap(items []string) bool {
+	for _, item := range items {
+		if !strings.Contains(item, "=") {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPairs returns the keys and values of the key=value pairs.
+func splitPairs(pairs []string) []string {
+	items := make([]string, 0, 2*len(pairs))
+	for _, pair := range pairs {
+		key, value, _ := strings.Cut(pair, "=")
+		items = append(items, key, value)
+	}
+	return items
+}
+
+// matchParams extracts the variables of specs from raw, the parameters of a
+// query or path-style parameter expression separated by sep. Parameters not
+// named after a variable are the keys and values of the first exploded
+// variable not found, which holds an associative array.
+func matchParams(raw, sep string, specs []varspec, vars map[string]string) bool {
+	named := make(map[string]bool)
+	for _, v := range specs {
+		named[v.name] = true
+	}
+	values := make(map[string][]string)
+	var unnamed []string
+	for _, param := range strings.Split(raw, sep) {
+		if param == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(param, "=")
+		key, err := url.PathUnescape(key)
+		if err != nil {
+			return false
+		}
+		value, err = url.PathUnescape(value)
+		if err != nil {
+			return false
+		}
+		if named[key] {
+			values[key] = append(values[key], value)
+		} else {
+			unnamed = append(unnamed, key, value)
+		}
+	}
+	for _, v := range specs {
+		if found, ok := values[v.name]; ok {
+			vars[v.name] = strings.Join(found, ",")
+		} else if v.explode && len(unnamed) > 0 {
+			vars[v.name] = strings.Join(unnamed, ",")
+			unnamed = nil
+		}
+	}
+	return true
+}
@@ -0,0 +1,364 @@
+// Package uritemplate implements RFC 6570 URI templates up to level 4. A
+// template can be expanded with variables, and a concrete URI can be matched
+// back against it to extract them.
+package uritemplate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidTemplate = errors.New("uritemplate: invalid template")
+	ErrInvalidValue    = errors.New("uritemplate: invalid value")
+)
+
+// operator describes the expansion rules of an expression operator, see RFC
+// 6570 appendix A.
+type operator struct {
+	first    string
+	sep      string
+	named    bool
+	ifemp    string
+	reserved bool
+}
+
+var operators = map[byte]operator{
+	0:   {first: "", sep: ",", named: false, ifemp: "", reserved: false},
+	'+': {first: "", sep: ",", named: false, ifemp: "", reserved: true},
+	'.': {first: ".", sep: ".", named: false, ifemp: "", reserved: false},
+	'/': {first: "/", sep: "/", named: false, ifemp: "", reserved: false},
+	';': {first: ";", sep: ";", named: true, ifemp: "", reserved: false},
+	'?': {first: "?", sep: "&", named: true, ifemp: "=", reserved: false},
+	'&': {first: "&", sep: "&", named: true, ifemp: "=", reserved: false},
+	'#': {first: "#", sep: ",", named: false, ifemp: "", reserved: true},
+}
+
+type varspec struct {
+	name    string
+	prefix  int // 0 if no prefix modifier
+	explode bool
+}
+
+// part is either a literal or an expression of a template.
+type part struct {
+	literal string
+	opChar  byte
+	op      operator
+	vars    []varspec
+}
+
+func (p part) isExpression() bool {
+	return p.vars != nil
+}
+
+// Template is a parsed URI template.
+type Template struct {
+	raw   string
+	parts []part
+
+	// used for matching
+	re       *regexp.Regexp
+	captures []capture
+}
+
+// capture describes how a submatch of the matching regexp maps to variables.
+type capture struct {
+	name     string
+	sep      string // separator of exploded items or of parameters
+	params   []varspec
+	explode  bool
+	reserved bool
+}
+
+// Parse parses a URI template.
+func Parse(s string) (*Template, error) {
+	t := &Template{raw: s}
+	rest := s
+	for rest != "" {
+		i := strings.IndexAny(rest, "{}")
+		if i < 0 {
+			t.parts = append(t.parts, part{literal: rest})
+			break
+		}
+		if rest[i] == '}' {
+			return nil, fmt.Errorf("%w: unexpected '}' in %q", ErrInvalidTemplate, s)
+		}
+		if i > 0 {
+			t.parts = append(t.parts, part{literal: rest[:i]})
+		}
+		end := strings.IndexByte(rest[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unclosed expression in %q", ErrInvalidTemplate, s)
+		}
+		expr, err := parseExpression(rest[i+1 : i+end])
+		if err != nil {
+			return nil, fmt.Errorf("%w in %q", err, s)
+		}
+		t.parts = append(t.parts, expr)
+		rest = rest[i+end+1:]
+	}
+	if err := t.compile(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// MustParse is like [Parse] but panics if the template cannot be parsed.
+func MustParse(s string) *Template {
+	t, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func parseExpression(expr string) (part, error) {
+	p := part{}
+	if expr != "" && strings.IndexByte("+#./;?&", expr[0]) >= 0 {
+		p.opChar = expr[0]
+		expr = expr[1:]
+	} else if expr != "" && strings.IndexByte("=,!@|", expr[0]) >= 0 {
+		return p, fmt.Errorf("%w: reserved operator %q", ErrInvalidTemplate, expr[0])
+	}
+	p.op = operators[p.opChar]
+
+	for _, spec := range strings.Split(expr, ",") {
+		v := varspec{}
+		if name, ok := strings.CutSuffix(spec, "*"); ok {
+			v.explode = true
+			spec = name
+		} else if name, length, ok := strings.Cut(spec, ":"); ok {
+			n, err := strconv.Atoi(length)
+			if err != nil || n <= 0 || n >= 10000 || length[0] == '0' {
+				return p, fmt.Errorf("%w: invalid prefix %q", ErrInvalidTemplate, length)
+			}
+			v.prefix = n
+			spec = name
+		}
+		if !validVarname(spec) {
+			return p, fmt.Errorf("%w: invalid variable name %q", ErrInvalidTemplate, spec)
+		}
+		v.name = spec
+		p.vars = append(p.vars, v)
+	}
+	return p, nil
+}
+
+func validVarname(name string) bool {
+	if name == "" || name[0] == '.' || name[len(name)-1] == '.' {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '.':
+		case c == '%' && i+2 < len(name) && isHex(name[i+1]) && isHex(name[i+2]):
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the template as it was parsed.
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Varnames returns the names of the variables of the template, in order.
+func (t *Template) Varnames() []string {
+	var names []string
+	for _, p := range t.parts {
+		for _, v := range p.vars {
+			names = append(names, v.name)
+		}
+	}
+	return names
+}
+
+// Expand expands the template with the given variables. A value is either a
+// string, a []string list or a map[string]string associative array, whose
+// keys are expanded in sorted order. Missing variables, nil values and empty
+// lists and maps are undefined and expand to nothing.
+func (t *Template) Expand(vars map[string]any) (string, error) {
+	var b strings.Builder
+	for _, p := range t.parts {
+		if !p.isExpression() {
+			b.WriteString(p.literal)
+			continue
+		}
+		first := true
+		for _, v := range p.vars {
+			value, ok := vars[v.name]
+			if !ok || value == nil {
+				continue
+			}
+			var err error
+			switch value := value.(type) {
+			case string:
+				writeSep(&b, p.op, &first)
+				expandString(&b, p.op, v, value)
+			case []string:
+				if len(value) == 0 {
+					continue
+				}
+				writeSep(&b, p.op, &first)
+				expandList(&b, p.op, v, value)
+			case map[string]string:
+				if len(value) == 0 {
+					continue
+				}
+				writeSep(&b, p.op, &first)
+				expandMap(&b, p.op, v, value)
+			default:
+				err = fmt.Errorf("%w: unsupported type %T for %q", ErrInvalidValue, value, v.name)
+			}
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+func writeSep(b *strings.Builder, op operator, first *bool) {
+	if *first {
+		b.WriteString(op.first)
+		*first = false
+	} else {
+		b.WriteString(op.sep)
+	}
+}
+
+func expandString(b *strings.Builder, op operator, v varspec, value string) {
+	if op.named {
+		b.WriteString(v.name)
+		if value == "" {
+			b.WriteString(op.ifemp)
+			return
+		}
+		b.WriteByte('=')
+	}
+	if v.prefix > 0 && utf8.RuneCountInString(value) > v.prefix {
+		i := 0
+		for n := range value {
+			if i == v.prefix {
+				value = value[:n]
+				break
+			}
+			i++
+		}
+	}
+	b.WriteString(encode(value, op.reserved))
+}
+
+func expandList(b *strings.Builder, op operator, v varspec, value []string) {
+	if !v.explode {
+		if op.named {
+			b.WriteString(v.name)
+			b.WriteByte('=')
+		}
+		for i, item := range value {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(encode(item, op.reserved))
+		}
+		return
+	}
+	for i, item := range value {
+		if i > 0 {
+			b.WriteString(op.sep)
+		}
+		if op.named {
+			b.WriteString(v.name)
+			if item == "" {
+				b.WriteString(op.ifemp)
+				continue
+			}
+			b.WriteByte('=')
+		}
+		b.WriteString(encode(item, op.reserved))
+	}
+}
+
+func expandMap(b *strings.Builder, op operator, v varspec, value map[string]string) {
+	keys := make([]string, 0, len(value))
+	for k := range value {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if !v.explode {
+		if op.named {
+			b.WriteString(v.name)
+			b.WriteByte('=')
+		}
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(encode(k, op.reserved))
+			b.WriteByte(',')
+			b.WriteString(encode(value[k], op.reserved))
+		}
+		return
+	}
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(op.sep)
+		}
+		b.WriteString(encode(k, op.reserved))
+		if op.named && value[k] == "" {
+			b.WriteString(op.ifemp)
+			continue
+		}
+		b.WriteByte('=')
+		b.WriteString(encode(value[k], op.reserved))
+	}
+}
+
+const upperhex = "0123456789ABCDEF"
+
+// encode percent-encodes s, keeping unreserved characters, and reserved
+// characters and percent-encoded triplets if reserved is set.
+func encode(s string, reserved bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isUnreserved(c):
+			b.WriteByte(c)
+		case reserved && isReserved(c):
+			b.WriteByte(c)
+		case reserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b.WriteString(s[i : i+3])
+			i += 2
+		default:
+			b.WriteByte('%')
+			b.WriteByte(upperhex[c>>4])
+			b.WriteByte(upperhex[c&15])
+		}
+	}
+	return b.String()
+}
+
+func isUnreserved(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isReserved(c byte) bool {
+	return strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
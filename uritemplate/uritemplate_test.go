@@ -0,0 +1,247 @@
+package uritemplate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// vars are the example variables of RFC 6570 section 3.2.
+var vars = map[string]any{
+	"count":      []string{"one", "two", "three"},
+	"dom":        []string{"example", "com"},
+	"dub":        "me/too",
+	"hello":      "Hello World!",
+	"half":       "50%",
+	"var":        "value",
+	"who":        "fred",
+	"base":       "http://example.com/home/",
+	"path":       "/foo/bar",
+	"list":       []string{"red", "green", "blue"},
+	"keys":       map[string]string{"comma": ",", "dot": ".", "semi": ";"},
+	"v":          "6",
+	"x":          "1024",
+	"y":          "768",
+	"empty":      "",
+	"empty_keys": map[string]string{},
+	"undef":      nil,
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		// level 1
+		{"{var}", "value"},
+		{"{hello}", "Hello%20World%21"},
+		{"{half}", "50%25"},
+		{"O{empty}X", "OX"},
+		{"O{undef}X", "OX"},
+		// level 2
+		{"{+var}", "value"},
+		{"{+hello}", "Hello%20World!"},
+		{"{+path}/here", "/foo/bar/here"},
+		{"here?ref={+path}", "here?ref=/foo/bar"},
+		{"X{#var}", "X#value"},
+		{"X{#hello}", "X#Hello%20World!"},
+		// level 3
+		{"map?{x,y}", "map?1024,768"},
+		{"{x,hello,y}", "1024,Hello%20World%21,768"},
+		{"{+x,hello,y}", "1024,Hello%20World!,768"},
+		{"{+path,x}/here", "/foo/bar,1024/here"},
+		{"{#x,hello,y}", "#1024,Hello%20World!,768"},
+		{"X{.var}", "X.value"},
+		{"X{.x,y}", "X.1024.768"},
+		{"{/var}", "/value"},
+		{"{/var,x}/here", "/value/1024/here"},
+		{"{;x,y}", ";x=1024;y=768"},
+		{"{;x,y,empty}", ";x=1024;y=768;empty"},
+		{"{?x,y}", "?x=1024&y=768"},
+		{"{?x,y,empty}", "?x=1024&y=768&empty="},
+		{"?fixed=yes{&x}", "?fixed=yes&x=1024"},
+		{"{&x,y,empty}", "&x=1024&y=768&empty="},
+		// level 4
+		{"{var:3}", "val"},
+		{"{var:30}", "value"},
+		{"{list}", "red,green,blue"},
+		{"{list*}", "red,green,blue"},
+		{"{keys}", "comma,%2C,dot,.,semi,%3B"},
+		{"{keys*}", "comma=%2C,dot=.,semi=%3B"},
+		{"{+path:6}/here", "/foo/b/here"},
+		{"{+list}", "red,green,blue"},
+		{"{+keys*}", "comma=,,dot=.,semi=;"},
+		{"{#list*}", "#red,green,blue"},
+		{"X{.list}", "X.red,green,blue"},
+		{"X{.list*}", "X.red.green.blue"},
+		{"X{.empty_keys}", "X"},
+		{"{/var:1,var}", "/v/value"},
+		{"{/list*}", "/red/green/blue"},
+		{"{/list*,path:4}", "/red/green/blue/%2Ffoo"},
+		{"{/keys*}", "/comma=%2C/dot=./semi=%3B"},
+		{"{;hello:5}", ";hello=Hello"},
+		{"{;list}", ";list=red,green,blue"},
+		{"{;list*}", ";list=red;list=green;list=blue"},
+		{"{;keys*}", ";comma=%2C;dot=.;semi=%3B"},
+		{"{?var:3}", "?var=val"},
+		{"{?list}", "?list=red,green,blue"},
+		{"{?list*}", "?list=red&list=green&list=blue"},
+		{"{?keys*}", "?comma=%2C&dot=.&semi=%3B"},
+		{"{&var:3}", "&var=val"},
+		{"{&list*}", "&list=red&list=green&list=blue"},
+	}
+	for _, tt := range tests {
+		got, err := MustParse(tt.template).Expand(vars)
+		if err != nil {
+			t.Errorf("Expand(%q) failed: %v", tt.template, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+
+	if _, err := MustParse("{var}").Expand(map[string]any{"var": 1}); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, template := range []string{
+		"{var", "var}", "{}", "{=var}", "{var:0}", "{var:10000}", "{var:x}", "{va r}", "{.var.}",
+	} {
+		if _, err := Parse(template); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("Parse(%q): expected ErrInvalidTemplate, got %v", template, err)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		template string
+		uri      string
+		want     map[string]string
+	}{
+		{"file:///{name}", "file:///readme.md", map[string]string{"name": "readme.md"}},
+		{"file:///{name}", "file:///a%20b", map[string]string{"name": "a b"}},
+		{"file:///{+path}", "file:///docs/guide/intro.md", map[string]string{"path": "docs/guide/intro.md"}},
+		{"db://{table}/{id}", "db://users/42", map[string]string{"table": "users", "id": "42"}},
+		{"db://{table}{/id}", "db://users/42", map[string]string{"table": "users", "id": "42"}},
+		{"repo://{owner}/{repo}{/path*}", "repo://vibeus/mcp/a/b/c", map[string]string{"owner": "vibeus", "repo": "mcp", "path": "a,b,c"}},
+		{"file://{name}{.ext}", "file://report.pdf", map[string]string{"name": "report", "ext": "pdf"}},
+		{"search://{index}{?q,limit}", "search://docs?q=hello%20world&limit=10", map[string]string{"index": "docs", "q": "hello world", "limit": "10"}},
+		{"search://{index}{?q,limit}", "search://docs?limit=10&q=x", map[string]string{"index": "docs", "q": "x", "limit": "10"}},
+		{"search://{index}{?q,limit}", "search://docs", map[string]string{"index": "docs"}},
+		{"search://{index}{?tag*}", "search://docs?tag=a&tag=b", map[string]string{"index": "docs", "tag": "a,b"}},
+		{"page://{name}{#section}", "page://home#intro", map[string]string{"name": "home", "section": "intro"}},
+		{"page://{name}{#section}", "page://home", map[string]string{"name": "home"}},
+		{"map://{name}{;x,y}", "map://world;x=1;y=2", map[string]string{"name": "world", "x": "1", "y": "2"}},
+		{"point://{x,y}", "point://1024,768", map[string]string{"x": "1024", "y": "768"}},
+		{"static://readme", "static://readme", map[string]string{}},
+		{"color://{name}{;list*}", "color://set;list=red;list=green", map[string]string{"name": "set", "list": "red,green"}},
+		{"search://{index}{?keys*}", "search://docs?lang=go&sort=", map[string]string{"index": "docs", "keys": "lang,go,sort,"}},
+		{"{var}", "", map[string]string{"var": ""}},
+	}
+	for _, tt := range tests {
+		got, ok := MustParse(tt.template).Match(tt.uri)
+		if !ok {
+			t.Errorf("Match(%q, %q) failed", tt.template, tt.uri)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.template, tt.uri, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct{ template, uri string }{
+		{"file:///{name}", "file:///a/b"},
+		{"file:///{name}", "http:///a"},
+		{"db://{table}/{id}", "db://users"},
+		{"static://readme", "static://readme2"},
+	} {
+		if got, ok := MustParse(tt.template).Match(tt.uri); ok {
+			t.Errorf("Match(%q, %q) = %v, expected no match", tt.template, tt.uri, got)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	template := MustParse("repo://{owner}/{repo}/blob{/path*}{?ref}")
+	values := map[string]any{"owner": "vibe us", "repo": "mcp", "path": []string{"a", "b.go"}, "ref": "v1/2"}
+	uri, err := template.Expand(values)
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	got, ok := template.Match(uri)
+	if !ok {
+		t.Fatalf("Match(%q) failed", uri)
+	}
+	want := map[string]string{"owner": "vibe us", "repo": "mcp", "path": "a,b.go", "ref": "v1/2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Match(%q) = %v, want %v", uri, got, want)
+	}
+	if names := template.Varnames(); !reflect.DeepEqual(names, []string{"owner", "repo", "path", "ref"}) {
+		t.Errorf("Unexpected varnames: %v", names)
+	}
+}
+
+// TestMatchExpansions matches the expansions of the level 4 examples of RFC
+// 6570 back against their templates.
+func TestMatchExpansions(t *testing.T) {
+	keys := "comma,,,dot,.,semi,;"
+	list := "red,green,blue"
+	tests := []struct {
+		template string
+		want     map[string]string
+	}{
+		{"{var:3}", map[string]string{"var": "val"}},
+		{"{var:30}", map[string]string{"var": "value"}},
+		{"{list}", map[string]string{"list": list}},
+		{"{list*}", map[string]string{"list": list}},
+		{"{keys}", map[string]string{"keys": keys}},
+		{"{keys*}", map[string]string{"keys": keys}},
+		{"{+path:6}/here", map[string]string{"path": "/foo/b"}},
+		{"{+list}", map[string]string{"list": list}},
+		// reserved expansions of maps cannot be told from lists
+		{"{+keys*}", map[string]string{"keys": "comma=,,dot=.,semi=;"}},
+		{"{#list*}", map[string]string{"list": list}},
+		{"X{.list}", map[string]string{"list": list}},
+		{"X{.list*}", map[string]string{"list": list}},
+		{"X{.empty_keys}", map[string]string{}},
+		{"{/var:1,var}", map[string]string{"var": "value"}},
+		{"{/list*}", map[string]string{"list": list}},
+		{"{/list*,path:4}", map[string]string{"list": list, "path": "/foo"}},
+		{"{/keys*}", map[string]string{"keys": keys}},
+		{"{;hello:5}", map[string]string{"hello": "Hello"}},
+		{"{;list}", map[string]string{"list": list}},
+		{"{;list*}", map[string]string{"list": list}},
+		{"{;keys*}", map[string]string{"keys": keys}},
+		{"{?var:3}", map[string]string{"var": "val"}},
+		{"{?list}", map[string]string{"list": list}},
+		{"{?list*}", map[string]string{"list": list}},
+		{"{?keys*}", map[string]string{"keys": keys}},
+		{"{&var:3}", map[string]string{"var": "val"}},
+		{"{&list*}", map[string]string{"list": list}},
+		// empty values
+		{"{empty}", map[string]string{"empty": ""}},
+		{"O{empty}X", map[string]string{"empty": ""}},
+		{"{;x,y,empty}", map[string]string{"x": "1024", "y": "768", "empty": ""}},
+		{"{?x,y,empty}", map[string]string{"x": "1024", "y": "768", "empty": ""}},
+	}
+	for _, tt := range tests {
+		template := MustParse(tt.template)
+		uri, err := template.Expand(vars)
+		if err != nil {
+			t.Errorf("Expand(%q) failed: %v", tt.template, err)
+			continue
+		}
+		got, ok := template.Match(uri)
+		if !ok {
+			t.Errorf("Match(%q, %q) failed", tt.template, uri)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.template, uri, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+var (
+	DefaultWatchDebounce     time.Duration = 100 * time.Millisecond
+	DefaultWatchMaxWait      time.Duration = 1 * time.Second
+	DefaultWatchPollInterval time.Duration = 1 * time.Second
+)
+
+var errNoNativeWatcher = errors.New("mcp: native file watching is not supported")
+
+// WatchOp is a set of changes to a file.
+type WatchOp uint8
+
+const (
+	WatchCreate WatchOp = 1 << iota
+	WatchWrite
+	WatchRemove
+	// WatchRescan reports that changes were lost, for instance because the
+	// queue of the native watcher overflowed, and that the whole tree should
+	// be scanned again. It is reported for the root, with an empty Path.
+	WatchRescan
+)
+
+// WatchEvent reports changes to a file or directory.
+type WatchEvent struct {
+	// Path is the slash-separated path of the file, relative to the watched
+	// directory.
+	Path  string
+	Op    WatchOp
+	IsDir bool
+}
+
+type WatchOptions struct {
+	// Debounce is the quiet period after which accumulated changes are
+	// reported, DefaultWatchDebounce if zero.
+	Debounce time.Duration
+	// MaxWait bounds the time changes are held back while changes keep
+	// happening, DefaultWatchMaxWait if zero.
+	MaxWait time.Duration
+	// PollInterval is the interval between scans of the polling watcher,
+	// DefaultWatchPollInterval if zero.
+	PollInterval time.Duration
+	// Poll forces polling even where native notifications are available.
+	Poll bool
+}
+
+// WatchDir watches the directory tree rooted at dir until ctx is done. Changes
+// are debounced: fn is called with the changes accumulated once no change
+// happened for the debounce period, or at the latest after the max wait,
+// merged per path and sorted by path.
+//
+// It uses inotify on Linux, and polls the tree elsewhere or when inotify is not
+// available. It returns an error if dir cannot be watched.
+func WatchDir(ctx context.Context, dir string, opts WatchOptions, fn func([]WatchEvent)) error {
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultWatchDebounce
+	}
+	if opts.MaxWait <= 0 {
+		opts.MaxWait = DefaultWatchMaxWait
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultWatchPollInterval
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := make(chan WatchEvent, 64)
+	err := errNoNativeWatcher
+	if !opts.Poll {
+		err = watchNative(ctx, dir, events)
+	}
+	if err != nil {
+		err = watchPoll(ctx, dir, opts.PollInterval, events)
+	}
+	if err != nil {
+		return err
+	}
+	debounceEvents(ctx, events, opts.Debounce, opts.MaxWait, fn)
+	return nil
+}
+
+// debounceEvents merges events until none arrived for d, or until maxWait
+// passed since the first of them, then calls fn with them.
+func debounceEvents(ctx context.Context, events <-chan WatchEvent, d, maxWait time.Duration, fn func([]WatchEvent)) {
+	pending := make(map[string]WatchEvent)
+	var deadline time.Time
+	timer := time.NewTimer(d)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-events:
+			if len(pending) == 0 {
+				deadline = time.Now().Add(maxWait)
+			}
+			if prev, ok := pending[e.Path]; ok {
+				e.Op |= prev.Op
+				e.IsDir = e.IsDir || prev.IsDir
+			}
+			pending[e.Path] = e
+			timer.Reset(min(d, time.Until(deadline)))
+		case <-timer.C:
+			batch := make([]WatchEvent, 0, len(pending))
+			for _, e := range pending {
+				batch = append(batch, e)
+			}
+			sort.Slice(batch, func(i, j int) bool { return batch[i].Path < batch[j].Path })
+			clear(pending)
+			fn(batch)
+		}
+	}
+}
+
+func sendEvent(ctx context.Context, events chan<- WatchEvent, e WatchEvent) {
+	select {
+	case events <- e:
+	case <-ctx.Done():
+	}
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+// watchPoll scans the tree rooted at dir every interval and reports the
+// differences between scans.
+func watchPoll(ctx context.Context, dir string, interval time.Duration, events chan<- WatchEvent) error {
+	prev, err := scanDir(dir)
+	if err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			next, err := scanDir(dir)
+			if err != nil {
+				continue
+			}
+			for name, state := range next {
+				old, ok := prev[name]
+				switch {
+				case !ok:
+					sendEvent(ctx, events, WatchEvent{Path: name, Op: WatchCreate, IsDir: state.isDir})
+				case !state.isDir && (old.size != state.size || !old.modTime.Equal(state.modTime)):
+					sendEvent(ctx, events, WatchEvent{Path: name, Op: WatchWrite})
+				}
+			}
+			for name, state := range prev {
+				if _, ok := next[name]; !ok {
+					sendEvent(ctx, events, WatchEvent{Path: name, Op: WatchRemove, IsDir: state.isDir})
+				}
+			}
+			prev = next
+		}
+	}()
+	return nil
+}
+
+func scanDir(dir string) (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if name == dir {
+				return err
+			}
+			return nil
+		}
+		if name == dir {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, name)
+		files[filepath.ToSlash(rel)] = fileState{size: info.Size(), modTime: info.ModTime(), isDir: d.IsDir()}
+		return nil
+	})
+	return files, err
+}
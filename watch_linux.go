@@ -0,0 +1,160 @@
+//go:build linux
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+// inotifyWatcher watches a directory tree with one inotify watch per
+// directory. Its fields are only used by the reading goroutine once started.
+type inotifyWatcher struct {
+	fd     int
+	file   *os.File
+	root   string
+	dirs   map[int32]string // slash-separated paths relative to root, "" for root
+	events chan<- WatchEvent
+}
+
+// watchNative watches the tree rooted at dir with inotify.
+func watchNative(ctx context.Context, dir string, events chan<- WatchEvent) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return err
+	}
+	// the file is non-blocking, so that reads wait in the runtime poller and
+	// are interrupted by Close
+	w := &inotifyWatcher{
+		fd:     fd,
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		root:   dir,
+		dirs:   make(map[int32]string),
+		events: events,
+	}
+	if _, err := w.addTree(""); err != nil {
+		w.file.Close()
+		return err
+	}
+	context.AfterFunc(ctx, func() {
+		w.file.Close()
+	})
+	go w.run(ctx)
+	return nil
+}
+
+// addTree watches the directory at rel and its subdirectories, and returns the
+// paths of the entries found in them.
+func (w *inotifyWatcher) addTree(rel string) ([]WatchEvent, error) {
+	var found []WatchEvent
+	top := filepath.Join(w.root, filepath.FromSlash(rel))
+	err := filepath.WalkDir(top, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if name == top {
+				return err
+			}
+			return nil
+		}
+		sub, _ := filepath.Rel(w.root, name)
+		sub = filepath.ToSlash(sub)
+		if sub == "." {
+			sub = ""
+		}
+		if name != top {
+			found = append(found, WatchEvent{Path: sub, Op: WatchCreate, IsDir: d.IsDir()})
+		}
+		if d.IsDir() {
+			wd, err := syscall.InotifyAddWatch(w.fd, name, inotifyMask)
+			if err != nil {
+				if name == top {
+					return err
+				}
+				return nil
+			}
+			w.dirs[int32(wd)] = sub
+		}
+		return nil
+	})
+	return found, err
+}
+
+// removeTree drops the watches of the directory at rel and its subdirectories.
+func (w *inotifyWatcher) removeTree(rel string) {
+	for wd, dir := range w.dirs {
+		if dir == rel || strings.HasPrefix(dir, rel+"/") {
+			syscall.InotifyRmWatch(w.fd, uint32(wd))
+			delete(w.dirs, wd)
+		}
+	}
+}
+
+func (w *inotifyWatcher) run(ctx context.Context) {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			start := offset + syscall.SizeofInotifyEvent
+			end := min(start+int(raw.Len), n)
+			name := buf[start:end]
+			if i := bytes.IndexByte(name, 0); i >= 0 {
+				name = name[:i]
+			}
+			w.handle(ctx, raw.Wd, raw.Mask, string(name))
+			offset = end
+		}
+	}
+}
+
+func (w *inotifyWatcher) handle(ctx context.Context, wd int32, mask uint32, name string) {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		// events were dropped, including possibly the creation of directories
+		// which are not watched yet
+		w.addTree("")
+		sendEvent(ctx, w.events, WatchEvent{Op: WatchRescan, IsDir: true})
+		return
+	}
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(w.dirs, wd)
+		return
+	}
+	dir, ok := w.dirs[wd]
+	if !ok || name == "" {
+		return
+	}
+	e := WatchEvent{Path: path.Join(dir, name), IsDir: mask&syscall.IN_ISDIR != 0}
+	switch {
+	case mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0:
+		e.Op = WatchCreate
+		if e.IsDir {
+			// files may have been created before the watch was added
+			found, _ := w.addTree(e.Path)
+			for _, f := range found {
+				sendEvent(ctx, w.events, f)
+			}
+		}
+	case mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+		e.Op = WatchRemove
+		if e.IsDir {
+			w.removeTree(e.Path)
+		}
+	case mask&(syscall.IN_MODIFY|syscall.IN_CLOSE_WRITE) != 0:
+		e.Op = WatchWrite
+	default:
+		return
+	}
+	sendEvent(ctx, w.events, e)
+}
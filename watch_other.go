@@ -0,0 +1,9 @@
+//go:build !linux
+
+package mcp
+
+import "context"
+
+func watchNative(ctx context.Context, dir string, events chan<- WatchEvent) error {
+	return errNoNativeWatcher
+}
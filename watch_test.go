@@ -0,0 +1,234 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchDir(t *testing.T) {
+	for _, poll := range []bool{false, true} {
+		name := "Native"
+		if poll {
+			name = "Poll"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "old.txt"), []byte("old"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			batches := make(chan []WatchEvent, 16)
+			opts := WatchOptions{Debounce: 50 * time.Millisecond, PollInterval: 20 * time.Millisecond, Poll: poll}
+			started := make(chan error, 1)
+			go func() {
+				started <- WatchDir(ctx, dir, opts, func(events []WatchEvent) { batches <- events })
+			}()
+			// let the watcher take its first look at the tree
+			time.Sleep(100 * time.Millisecond)
+
+			expect := func(t *testing.T, want map[string]WatchOp) {
+				t.Helper()
+				got := make(map[string]WatchOp)
+				deadline := time.After(2 * time.Second)
+				for len(got) < len(want) {
+					select {
+					case batch := <-batches:
+						for _, e := range batch {
+							got[e.Path] |= e.Op
+						}
+					case err := <-started:
+						t.Fatalf("WatchDir returned early: %v", err)
+					case <-deadline:
+						t.Fatalf("Timeout waiting for %v, got %v", want, got)
+					}
+				}
+				for path, op := range want {
+					if got[path]&op != op {
+						t.Errorf("Expected %v for %q, got %v", op, path, got[path])
+					}
+				}
+			}
+
+			t.Run("Burst", func(t *testing.T) {
+				for i := range 5 {
+					data := []byte{byte('a' + i)}
+					if err := os.WriteFile(filepath.Join(dir, "new.txt"), data, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "sub", "deep.txt"), []byte("deep"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				expect(t, map[string]WatchOp{"new.txt": WatchCreate, "sub": WatchCreate, "sub/deep.txt": WatchCreate})
+			})
+
+			t.Run("WriteAndRemove", func(t *testing.T) {
+				if err := os.WriteFile(filepath.Join(dir, "old.txt"), []byte("changed"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				expect(t, map[string]WatchOp{"old.txt": WatchWrite})
+				if err := os.Remove(filepath.Join(dir, "sub", "deep.txt")); err != nil {
+					t.Fatal(err)
+				}
+				expect(t, map[string]WatchOp{"sub/deep.txt": WatchRemove})
+			})
+
+			cancel()
+			select {
+			case err := <-started:
+				if err != nil {
+					t.Errorf("WatchDir failed: %v", err)
+				}
+			case <-time.After(1 * time.Second):
+				t.Error("WatchDir did not return after cancel")
+			}
+		})
+	}
+
+	if err := WatchDir(context.Background(), filepath.Join(t.TempDir(), "missing"), WatchOptions{}, nil); err == nil {
+		t.Error("Expected error watching a missing directory")
+	}
+}
+
+func TestDebounceMaxWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan WatchEvent)
+	batches := make(chan []WatchEvent, 1)
+	go debounceEvents(ctx, events, 50*time.Millisecond, 200*time.Millisecond, func(batch []WatchEvent) { batches <- batch })
+
+	// changes keep coming faster than the debounce period
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case <-ticker.C:
+			events <- WatchEvent{Path: "busy.log", Op: WatchWrite}
+		case batch := <-batches:
+			if len(batch) != 1 || batch[0].Path != "busy.log" {
+				t.Errorf("Unexpected batch: %v", batch)
+			}
+			return
+		case <-deadline:
+			t.Fatal("Timeout waiting for the max wait to flush changes")
+		}
+	}
+}
+
+// failedChanges records the error of a [CapResourcesWatcher].
+type failedChanges struct {
+	err error
+}
+
+func (c *failedChanges) ListChanged()       {}
+func (c *failedChanges) Updated(uri string) {}
+func (c *failedChanges) Failed(err error)   { c.err = err }
+
+func TestResourcesWatchFailed(t *testing.T) {
+	provider := NewDirResourceProvider(filepath.Join(t.TempDir(), "missing"), "")
+	changes := &failedChanges{}
+	provider.Resources_Watch(context.Background(), changes)
+	if changes.err == nil {
+		t.Error("Expected watching a missing directory to fail")
+	}
+}
+
+func TestResourceSubscriptions(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"watched.txt", "other.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	provider := NewDirResourceProvider(dir, "")
+	provider.WatchOptions.Debounce = 20 * time.Millisecond
+
+	// Setup test environment
+	serverProvider := NewTestServerImpl()
+	serverInstance := &ServerImpl{
+		MCPVersionNegotiator: serverProvider,
+		CapResourcesProvider: provider,
+	}
+	clientProvider := &testClientImpl{roots_ListChanged: make(chan struct{})}
+	clientInstance := &ClientImpl{
+		CapRootsProvider:    clientProvider,
+		CapSamplingProvider: clientProvider,
+	}
+
+	ts, err := SetupClientServer(serverInstance, clientInstance)
+	if err != nil {
+		t.Fatalf("Failed to setup test: %v", err)
+	}
+	defer ts.Cleanup()
+
+	received := make(chan string, 16)
+	ts.Client.OnResourcesListChanged(func() { received <- kMethodResourcesListChanged })
+	ts.Client.OnResourceUpdated(func(uri string) { received <- kMethodResourcesUpdated + " " + uri })
+
+	expect := func(t *testing.T, expected string) {
+		t.Helper()
+		select {
+		case got := <-received:
+			if got != expected {
+				t.Errorf("Expected %q, got %q", expected, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout waiting for %q", expected)
+		}
+	}
+
+	t.Run("Initialization", func(t *testing.T) {
+		ts.Init(t)
+		// let the watcher start
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	t.Run("Updated", func(t *testing.T) {
+		if err := ts.Client.ResourcesSubscribe(ts.Ctx, provider.URIOf("watched.txt")); err != nil {
+			t.Fatalf("ResourcesSubscribe failed: %v", err)
+		}
+		if !ts.Server.IsSubscribed(provider.URIOf("watched.txt")) {
+			t.Fatal("Expected server to record the subscription")
+		}
+		if err := os.WriteFile(filepath.Join(dir, "other.txt"), []byte("unwatched"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "watched.txt"), []byte("changed"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		expect(t, kMethodResourcesUpdated+" "+provider.URIOf("watched.txt"))
+	})
+
+	t.Run("ListChanged", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		expect(t, kMethodResourcesListChanged)
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		if err := ts.Client.ResourcesUnsubscribe(ts.Ctx, provider.URIOf("watched.txt")); err != nil {
+			t.Fatalf("ResourcesUnsubscribe failed: %v", err)
+		}
+		if ts.Server.IsSubscribed(provider.URIOf("watched.txt")) {
+			t.Fatal("Expected server to drop the subscription")
+		}
+		if err := os.WriteFile(filepath.Join(dir, "watched.txt"), []byte("again"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case got := <-received:
+			t.Errorf("Unexpected notification %q", got)
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+}